@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"feedback-app/config"
+	"feedback-app/db"
+	"feedback-app/models"
+	"feedback-app/repository"
+	"flag"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func main() {
+	emailAddr := flag.String("email", "", "Email address of the user to update")
+	role := flag.String("role", models.RoleAdmin, "Role to assign (admin or user)")
+	flag.Parse()
+
+	if *emailAddr == "" {
+		log.Fatal("-email is required")
+	}
+	if *role != models.RoleAdmin && *role != models.RoleUser {
+		log.Fatalf("Unknown role %q", *role)
+	}
+
+	// 1. Load Configuration
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	gormDB, err := db.InitDB(cfg)
+	if err != nil {
+		log.Fatalf("Failed to init db: %v", err)
+	}
+	userRepo := repository.NewUserRepository(gormDB)
+
+	// 2. Find or create the user
+	addr := strings.TrimSpace(*emailAddr)
+	user, err := userRepo.FindByEmail(addr)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		user = &models.User{Email: addr, Role: models.RoleUser, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+		if err := userRepo.Create(user); err != nil {
+			log.Fatalf("Failed to create user: %v", err)
+		}
+		log.Printf("Created user %s", addr)
+	} else if err != nil {
+		log.Fatalf("Failed to look up user: %v", err)
+	}
+
+	// 3. Assign the role
+	if err := userRepo.UpdateRole(user.ID, *role); err != nil {
+		log.Fatalf("Failed to update role: %v", err)
+	}
+	log.Printf("User %s now has role %q", addr, *role)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"feedback-app/config"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const usage = `Usage: go run cmd/config/main.go print [--redacted]
+
+Prints every configuration key with its effective value and where it came
+from (env, CONFIG_FILE, a *_FILE secret or the default). The output is valid
+.env syntax. Use --redacted to mask secrets before sharing it.
+`
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "print" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	printCmd := flag.NewFlagSet("print", flag.ExitOnError)
+	redact := printCmd.Bool("redacted", false, "Mask secrets such as JWT_SECRET and SMTP_PASSWORD")
+	printCmd.Usage = func() {
+		fmt.Fprint(printCmd.Output(), usage)
+		printCmd.PrintDefaults()
+	}
+	_ = printCmd.Parse(os.Args[2:])
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	for _, setting := range cfg.Settings(*redact) {
+		fmt.Printf("%s=%s # %s\n", setting.Key, quote(setting.Value), setting.Source)
+	}
+}
+
+// quote wraps values that .env parsers would otherwise split or truncate.
+func quote(value string) string {
+	if value == "" || strings.ContainsAny(value, " \t#\"'\\") {
+		return strconv.Quote(value)
+	}
+	return value
+}
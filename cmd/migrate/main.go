@@ -1,15 +1,38 @@
 package main
 
 import (
+	"bufio"
 	"feedback-app/config"
 	"feedback-app/db"
 	"flag"
+	"fmt"
 	"log"
+	"os"
+	"strconv"
+	"strings"
 )
 
+const usage = `Usage: go run cmd/migrate/main.go [flags] [command]
+
+Commands:
+  up [N]      Apply all pending migrations, or the next N (default command)
+  down N      Revert the last N migrations
+  down        Revert all migrations (requires confirmation in production)
+  goto V      Migrate up or down to version V
+  version     Print the current schema version
+  status      List migrations and whether they are applied
+  force V     Set the version to V without running migrations (clears dirty state)
+
+Flags:
+`
+
 func main() {
 	force := flag.Int("force", -1, "Force migration version to clean dirty state")
 	down := flag.Bool("down", false, "Revert all migrations (tear down database)")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
 	// 1. Load Configuration
@@ -18,30 +41,124 @@ func main() {
 		log.Fatalf("Invalid configuration: %v", err)
 	}
 
-	// 2. Handle Force Flag
+	// 2. Translate legacy flags into commands
+	args := flag.Args()
 	if *force > -1 {
-		log.Printf("Forcing migration version to %d...", *force)
-		if err := db.ForceVersion(cfg.DatabaseDSN, *force); err != nil {
-			log.Fatalf("Force failed: %v", err)
-		}
-		log.Println("Force complete. You can now run the migration again.")
-		return
+		args = []string{"force", strconv.Itoa(*force)}
+	} else if *down {
+		args = []string{"down"}
+	}
+	if len(args) == 0 {
+		args = []string{"up"}
 	}
 
-	// 3. Handle Down Flag
-	if *down {
+	// 3. Dispatch
+	switch args[0] {
+	case "up":
+		if len(args) > 1 {
+			n := parsePositive(args[1], "up")
+			log.Printf("Applying %d migration(s)...", n)
+			if err := db.MigrateSteps(cfg.DatabaseDriver, cfg.DatabaseDSN, n); err != nil {
+				log.Fatalf("Migration failed: %v", err)
+			}
+			return
+		}
+		log.Println("Starting database migration...")
+		if err := db.RunMigrations(cfg.DatabaseDriver, cfg.DatabaseDSN); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		log.Println("Migration finished successfully.")
+
+	case "down":
+		if len(args) > 1 {
+			n := parsePositive(args[1], "down")
+			log.Printf("Reverting %d migration(s)...", n)
+			if err := db.MigrateSteps(cfg.DatabaseDriver, cfg.DatabaseDSN, -n); err != nil {
+				log.Fatalf("Revert failed: %v", err)
+			}
+			return
+		}
+		if cfg.AppEnv == "production" && !confirmTeardown() {
+			log.Fatal("Teardown cancelled.")
+		}
 		log.Println("Reverting database migrations...")
-		if err := db.RevertMigrations(cfg.DatabaseDSN); err != nil {
+		if err := db.RevertMigrations(cfg.DatabaseDriver, cfg.DatabaseDSN); err != nil {
 			log.Fatalf("Revert failed: %v", err)
 		}
 		log.Println("Revert finished successfully.")
-		return
+
+	case "goto":
+		if len(args) < 2 {
+			log.Fatal("goto requires a version")
+		}
+		version := parsePositive(args[1], "goto")
+		log.Printf("Migrating to version %d...", version)
+		if err := db.MigrateTo(cfg.DatabaseDriver, cfg.DatabaseDSN, uint(version)); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+
+	case "version":
+		version, dirty, err := db.MigrationVersion(cfg.DatabaseDriver, cfg.DatabaseDSN)
+		if err != nil {
+			log.Fatalf("Failed to read version: %v", err)
+		}
+		if dirty {
+			fmt.Printf("%d (dirty)\n", version)
+			return
+		}
+		fmt.Println(version)
+
+	case "status":
+		infos, err := db.MigrationStatus(cfg.DatabaseDriver, cfg.DatabaseDSN)
+		if err != nil {
+			log.Fatalf("Failed to read status: %v", err)
+		}
+		for _, info := range infos {
+			state := "pending"
+			if info.Applied {
+				state = "applied"
+			}
+			if info.DirtyState {
+				state = "dirty"
+			}
+			fmt.Printf("%06d  %-8s %s\n", info.Version, state, info.Name)
+		}
+
+	case "force":
+		if len(args) < 2 {
+			log.Fatal("force requires a version")
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < -1 {
+			log.Fatalf("Invalid version %q", args[1])
+		}
+		log.Printf("Forcing migration version to %d...", version)
+		if err := db.ForceVersion(cfg.DatabaseDriver, cfg.DatabaseDSN, version); err != nil {
+			log.Fatalf("Force failed: %v", err)
+		}
+		log.Println("Force complete. You can now run the migration again.")
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+func parsePositive(value string, command string) int {
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Fatalf("%s expects a positive number, got %q", command, value)
 	}
+	return n
+}
 
-	// 4. Run Migrations (Up)
-	log.Println("Starting database migration...")
-	if err := db.RunMigrations(cfg.DatabaseDSN); err != nil {
-		log.Fatalf("Migration failed: %v", err)
+// confirmTeardown asks the operator to type the word "teardown" before all
+// migrations are reverted in production.
+func confirmTeardown() bool {
+	fmt.Print("APP_ENV is production. This drops every table. Type \"teardown\" to continue: ")
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
 	}
-	log.Println("Migration finished successfully.")
+	return strings.TrimSpace(answer) == "teardown"
 }
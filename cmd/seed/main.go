@@ -0,0 +1,194 @@
+package main
+
+import (
+	"errors"
+	"feedback-app/config"
+	"feedback-app/db"
+	"feedback-app/models"
+	"feedback-app/repository"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// seedEmailDomain marks every seeded user so a re-run can remove them first.
+const seedEmailDomain = "@seed.feedback.test"
+
+var (
+	subjects = []string{
+		"The login email", "Dark mode", "The feedback form", "Push notifications", "The settings screen",
+		"Search", "The onboarding flow", "Sync between devices", "The export feature", "Offline mode",
+	}
+	problems = []string{
+		"takes too long to arrive", "crashes on my phone", "is hard to find", "does not remember my choice",
+		"looks broken on small screens", "works great, thank you", "should support more languages",
+		"logs me out randomly", "needs a shortcut", "could be faster",
+	}
+	details = []string{
+		"", " This happens every day.", " Started after the last update.", " My whole team noticed it.",
+		" Would happily pay for this.", " Not urgent, just a thought.", " Screenshots available on request.",
+	}
+	tagPool    = []string{"ios", "android", "web", "billing", "performance", "ux", "auth", "sync"}
+	platforms  = []string{"ios", "android", "web"}
+	devices    = map[string][]string{"ios": {"iPhone 13", "iPhone 15 Pro", "iPad Air"}, "android": {"Pixel 8", "Galaxy S23", "OnePlus 11"}, "web": {"Chrome", "Firefox", "Safari"}}
+	locales    = []string{"en-US", "en-GB", "de-DE", "fr-FR", "es-ES", "ja-JP"}
+	statusBias = []string{
+		models.FeedbackStatusNew, models.FeedbackStatusNew, models.FeedbackStatusNew,
+		models.FeedbackStatusInReview, models.FeedbackStatusPlanned, models.FeedbackStatusInProgress,
+		models.FeedbackStatusDone, models.FeedbackStatusDeclined,
+	}
+)
+
+func main() {
+	seed := flag.Int64("seed", 1, "Random seed; the same seed produces the same data")
+	userCount := flag.Int("users", 50, "Number of users to create")
+	feedbackCount := flag.Int("feedback", 5000, "Number of feedback items to create")
+	days := flag.Int("days", 180, "Spread feedback timestamps over this many days before today")
+	clean := flag.Bool("clean", false, "Remove previously seeded users (and their data) first")
+	flag.Parse()
+
+	// 1. Load Configuration
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	if cfg.AppEnv == "production" {
+		log.Fatal("Refusing to seed a production database (APP_ENV=production)")
+	}
+	if *userCount <= 0 || *feedbackCount < 0 || *days <= 0 {
+		log.Fatal("-users and -days must be positive and -feedback non-negative")
+	}
+
+	gormDB, err := db.InitDB(cfg)
+	if err != nil {
+		log.Fatalf("Failed to init db: %v", err)
+	}
+	userRepo := repository.NewUserRepository(gormDB)
+	magicLinkRepo := repository.NewMagicLinkRepository(gormDB)
+	feedbackRepo := repository.NewFeedbackRepository(gormDB)
+	tagRepo := repository.NewTagRepository(gormDB)
+
+	rng := rand.New(rand.NewSource(*seed))
+	// Timestamps are offsets from midnight today, so a seed produces the same
+	// rows on the same day and the data always looks recent.
+	now := time.Now()
+	anchor := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	// 2. Optionally remove earlier seed data
+	if *clean {
+		removed, err := userRepo.DeleteByEmailSuffix(seedEmailDomain)
+		if err != nil {
+			log.Fatalf("Failed to clean seed data: %v", err)
+		}
+		log.Printf("Removed %d seeded users and their data", removed)
+	}
+
+	// 3. Users and magic links
+	users := make([]*models.User, 0, *userCount)
+	for i := 0; i < *userCount; i++ {
+		user, err := seedUser(userRepo, i, anchor.Add(-time.Duration(rng.Intn(*days*24))*time.Hour))
+		if err != nil {
+			log.Fatalf("Failed to seed user: %v", err)
+		}
+		if err := seedMagicLinks(magicLinkRepo, user, anchor); err != nil {
+			log.Fatalf("Failed to seed magic links: %v", err)
+		}
+		users = append(users, user)
+	}
+	log.Printf("Seeded %d users with used, expired and valid magic links", len(users))
+
+	// 4. Tags
+	tags, err := tagRepo.FindOrCreate(tagPool)
+	if err != nil {
+		log.Fatalf("Failed to seed tags: %v", err)
+	}
+
+	// 5. Feedback
+	const batchSize = 500
+	batch := make([]models.Feedback, 0, batchSize)
+	for i := 0; i < *feedbackCount; i++ {
+		batch = append(batch, fakeFeedback(rng, users, tags, anchor, *days))
+		if len(batch) == batchSize || i == *feedbackCount-1 {
+			if err := feedbackRepo.CreateBatch(batch, batchSize); err != nil {
+				log.Fatalf("Failed to seed feedback: %v", err)
+			}
+			batch = batch[:0]
+		}
+	}
+	log.Printf("Seeded %d feedback items over the last %d days (seed %d)", *feedbackCount, *days, *seed)
+}
+
+func seedUser(repo *repository.UserRepository, i int, createdAt time.Time) (*models.User, error) {
+	emailAddr := fmt.Sprintf("user%03d%s", i+1, seedEmailDomain)
+	user, err := repo.FindByEmail(emailAddr)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	user = &models.User{Email: emailAddr, Role: models.RoleUser, CreatedAt: createdAt, UpdatedAt: createdAt}
+	if err := repo.Create(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// seedMagicLinks gives each user one link in every state the auth flow
+// distinguishes: already used, expired and still valid.
+func seedMagicLinks(repo *repository.MagicLinkRepository, user *models.User, anchor time.Time) error {
+	links := []models.MagicLink{
+		{UserID: user.ID, Used: true, ExpiresAt: anchor.Add(-24 * time.Hour), CreatedAt: anchor.Add(-25 * time.Hour)},
+		{UserID: user.ID, Used: false, ExpiresAt: anchor.Add(-time.Hour), CreatedAt: anchor.Add(-2 * time.Hour)},
+		{UserID: user.ID, Used: false, ExpiresAt: time.Now().Add(24 * time.Hour), CreatedAt: time.Now()},
+	}
+	for i := range links {
+		links[i].Token = "seed-" + uuid.New().String()
+		if err := repo.Create(&links[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fakeFeedback(rng *rand.Rand, users []*models.User, tags []models.Tag, anchor time.Time, days int) models.Feedback {
+	user := users[rng.Intn(len(users))]
+	createdAt := anchor.Add(-time.Duration(rng.Int63n(int64(days) * int64(24*time.Hour))))
+	platform := platforms[rng.Intn(len(platforms))]
+
+	var itemTags []models.Tag
+	for _, tag := range tags {
+		if rng.Intn(6) == 0 {
+			itemTags = append(itemTags, tag)
+		}
+	}
+
+	content := pick(rng, subjects) + " " + pick(rng, problems) + "." + pick(rng, details)
+
+	return models.Feedback{
+		UserID:   &user.ID,
+		Content:  strings.TrimSpace(content),
+		Status:   pick(rng, statusBias),
+		Category: pick(rng, models.FeedbackCategories),
+		Metadata: models.Metadata{
+			"platform":    platform,
+			"device":      pick(rng, devices[platform]),
+			"app_version": fmt.Sprintf("1.%d.%d", rng.Intn(8), rng.Intn(10)),
+			"locale":      pick(rng, locales),
+		},
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+		Tags:      itemTags,
+	}
+}
+
+func pick(rng *rand.Rand, values []string) string {
+	return values[rng.Intn(len(values))]
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"feedback-app/models"
+	"feedback-app/utils"
+)
+
+// TestCommentsAccess checks that submitters only see and change what they
+// may on their own feedback.
+func TestCommentsAccess(t *testing.T) {
+	f := newAPIFixture(t)
+
+	note := &models.Comment{FeedbackID: f.feedback.ID, AuthorID: f.admin.ID, Body: "Internal: big customer", Visibility: models.CommentVisibilityInternal}
+	f.create(t, note)
+	other := &models.User{Email: "grace@example.com", Role: models.RoleUser}
+	f.create(t, other)
+	otherToken, err := utils.GenerateJWT(other.ID, testSecret, time.Hour)
+	if err != nil {
+		t.Fatalf("JWT: %v", err)
+	}
+
+	comments := fmt.Sprintf("/api/feedback/%d/comments", f.feedback.ID)
+	comment := func(id uint) string { return fmt.Sprintf("%s/%d", comments, id) }
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		token  string
+		want   int
+	}{
+		{"owner lists", http.MethodGet, comments, "", f.userToken, http.StatusOK},
+		{"other user lists", http.MethodGet, comments, "", otherToken, http.StatusNotFound},
+		{"other user comments", http.MethodPost, comments, `{"body": "Me too"}`, otherToken, http.StatusNotFound},
+		{"other user edits", http.MethodPatch, comment(f.userComment.ID), `{"body": "Changed"}`, otherToken, http.StatusNotFound},
+		{"owner edits staff reply", http.MethodPatch, comment(f.staffComment.ID), `{"body": "Changed"}`, f.userToken, http.StatusForbidden},
+		{"owner edits internal note", http.MethodPatch, comment(note.ID), `{"body": "Changed"}`, f.userToken, http.StatusNotFound},
+		{"owner edits own comment", http.MethodPatch, comment(f.userComment.ID), `{"body": "It happens on every export"}`, f.userToken, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+
+			rec := httptest.NewRecorder()
+			f.router.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status %d, want %d; body: %s", rec.Code, tt.want, rec.Body.String())
+			}
+			if strings.Contains(rec.Body.String(), note.Body) {
+				t.Fatalf("response contains the internal note: %s", rec.Body.String())
+			}
+		})
+	}
+
+	var edits []models.CommentEdit
+	if err := f.db.Where("comment_id = ?", f.userComment.ID).Find(&edits).Error; err != nil {
+		t.Fatalf("load edits: %v", err)
+	}
+	if len(edits) != 1 || edits[0].Body != f.userComment.Body || edits[0].EditorID != f.user.ID {
+		t.Fatalf("edits = %+v, want the original text by the author", edits)
+	}
+}
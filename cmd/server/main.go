@@ -1,39 +1,161 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"feedback-app/config"
 	"feedback-app/controllers"
 	"feedback-app/db"
 	"feedback-app/middleware"
+	"feedback-app/openapi"
 	"feedback-app/platform/email"
+	"feedback-app/platform/push"
 	"feedback-app/platform/slack"
 	"feedback-app/repository"
 	"feedback-app/services"
+	"feedback-app/templates"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+// shutdownTimeout bounds how long in-flight requests may take to finish once
+// the server is asked to stop.
+const shutdownTimeout = 15 * time.Second
+
 func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("Invalid configuration: %v", err)
 	}
 
-	gormDB, err := db.InitDB(cfg.DatabaseDSN)
+	if cfg.MigrateOnStart {
+		log.Println("Running database migrations before start...")
+		if err := db.WaitForDatabase(cfg.DatabaseDriver, cfg.DatabaseDSN, time.Duration(cfg.DBPool.ConnectTimeoutSeconds)*time.Second); err != nil {
+			log.Fatalf("Database unavailable: %v", err)
+		}
+		if err := db.RunMigrationsLocked(cfg.DatabaseDriver, cfg.DatabaseDSN, time.Duration(cfg.MigrateLockTimeout)*time.Second); err != nil {
+			log.Fatalf("Migration on start failed: %v", err)
+		}
+	}
+
+	expectedSchemaVersion, err := db.LatestMigrationVersion(cfg.DatabaseDriver)
+	if err != nil {
+		log.Fatalf("Failed to read embedded migrations: %v", err)
+	}
+
+	gormDB, err := db.InitDB(cfg)
 	if err != nil {
 		log.Fatalf("Failed to init db: %v", err)
 	}
 
+	emailClient, err := email.NewClient(cfg.Email, cfg.SMTP)
+	if err != nil {
+		log.Fatalf("Failed to configure email provider: %v", err)
+	}
+
+	// Templates are embedded; in development they are read from disk instead
+	// and reloaded on change, as long as the server runs from the repo root.
+	emailTemplateFS, err := fs.Sub(templates.FS, "email")
+	if err != nil {
+		log.Fatalf("Failed to load email templates: %v", err)
+	}
+	watchTemplates := false
+	if cfg.AppEnv == "development" {
+		if _, err := os.Stat("templates/email"); err == nil {
+			emailTemplateFS = os.DirFS("templates/email")
+			watchTemplates = true
+		}
+	}
+	emailTemplates, err := email.NewTemplateRegistry(emailTemplateFS)
+	if err != nil {
+		log.Fatalf("Invalid email templates: %v", err)
+	}
+	if watchTemplates {
+		go emailTemplates.Watch(ctx, time.Second)
+	}
+
+	srv, err := newServer(cfg, gormDB, emailClient, emailTemplates, expectedSchemaVersion)
+	if err != nil {
+		log.Fatalf("Failed to set up server: %v", err)
+	}
+	queueDone := make(chan struct{})
+	go func() {
+		srv.emailQueue.Run(ctx)
+		close(queueDone)
+	}()
+
+	httpServer := &http.Server{Addr: cfg.ServerPort, Handler: srv.router}
+	go func() {
+		log.Printf("Server starting on %s", cfg.ServerPort)
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Server failed to start:", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Server shutdown: %v", err)
+	}
+
+	// The queue workers finish the message they are sending; only then can
+	// the pooled SMTP connections be closed.
+	<-queueDone
+	if closer, ok := emailClient.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Printf("Failed to close email client: %v", err)
+		}
+	}
+}
+
+// server is the wired application: the router and the email queue, whose
+// workers main starts.
+type server struct {
+	router     *gin.Engine
+	emailQueue *services.EmailQueue
+}
+
+// newServer wires the repositories, services and controllers on gormDB and
+// registers every route.
+func newServer(cfg *config.Config, gormDB *gorm.DB, emailClient email.Client, emailTemplates *email.TemplateRegistry, expectedSchemaVersion uint) (*server, error) {
 	userRepo := repository.NewUserRepository(gormDB)
 	magicLinkRepo := repository.NewMagicLinkRepository(gormDB)
 	feedbackRepo := repository.NewFeedbackRepository(gormDB)
+	tagRepo := repository.NewTagRepository(gormDB)
+	healthRepo := repository.NewHealthRepository(gormDB)
+	emailRepo := repository.NewEmailRepository(gormDB)
+	challengeRepo := repository.NewChallengeRepository(gormDB)
+	commentRepo := repository.NewCommentRepository(gormDB)
+	boardRepo := repository.NewBoardRepository(gormDB)
+	releaseRepo := repository.NewReleaseRepository(gormDB)
+	siteRepo := repository.NewSiteRepository(gormDB)
 
 	slackClient := slack.NewMockClient()
-	emailClient := email.NewSMTPClient(cfg.SMTP)
 
-	authService := services.NewAuthService(userRepo, magicLinkRepo, emailClient, services.AuthConfig{
+	emailQueue := services.NewEmailQueue(emailRepo, emailClient, services.EmailQueueConfig{
+		Workers:      cfg.EmailQueue.Workers,
+		MaxAttempts:  cfg.EmailQueue.MaxAttempts,
+		RetryBase:    time.Duration(cfg.EmailQueue.RetryBaseSeconds) * time.Second,
+		PollInterval: time.Duration(cfg.EmailQueue.PollSeconds) * time.Second,
+	})
+
+	authService := services.NewAuthService(userRepo, magicLinkRepo, emailQueue, emailTemplates, services.AuthConfig{
 		JWTSecret:     cfg.JWTSecret,
 		JWTExpiration: time.Duration(cfg.JWTTokenExpireMinutes) * time.Minute,
 		AppURL:        cfg.AppURL,
@@ -42,30 +164,251 @@ func main() {
 		LoginLinkTTL:  time.Duration(cfg.LoginLinkExpireMinutes) * time.Minute,
 	})
 
-	feedbackService := services.NewFeedbackService(feedbackRepo, slackClient)
+	var pushClient push.Client
+	if cfg.PushEnabled {
+		pushClient = push.NewMockClient()
+	}
+	notificationService := services.NewNotificationService(userRepo, boardRepo, emailQueue, pushClient, emailTemplates, services.NotificationConfig{
+		AppURL:      cfg.AppURL,
+		Secret:      cfg.JWTSecret,
+		ReplyDomain: cfg.InboundEmail.Domain,
+	})
+
+	challengeService := services.NewChallengeService(
+		challengeRepo,
+		cfg.JWTSecret,
+		cfg.Guest.Difficulty,
+		time.Duration(cfg.Guest.ChallengeTTLMinutes)*time.Minute,
+	)
+	feedbackService := services.NewFeedbackService(feedbackRepo, slackClient, challengeService)
+	accountService := services.NewAccountService(userRepo)
+	commentService := services.NewCommentService(feedbackRepo, commentRepo)
+	inboundEmailService := services.NewInboundEmailService(
+		services.NewReplyAddresses(cfg.JWTSecret, cfg.InboundEmail.Domain),
+		userRepo,
+		commentService,
+	)
+	adminService := services.NewAdminService(feedbackRepo, tagRepo, userRepo, emailRepo, commentRepo, notificationService)
+	boardService := services.NewBoardService(boardRepo, feedbackRepo, notificationService, services.BoardConfig{
+		MinAccountAge:  time.Duration(cfg.Board.VoteMinAccountAgeHours) * time.Hour,
+		VotesPerHour:   cfg.Board.VotesPerHour,
+		TrendingWindow: time.Duration(cfg.Board.TrendingDays) * 24 * time.Hour,
+	})
+	releaseService := services.NewReleaseService(releaseRepo, boardService, notificationService)
+	siteService := services.NewSiteService(siteRepo, cfg.AppURL)
+	healthService := services.NewHealthService(healthRepo, expectedSchemaVersion)
 
 	authController := controllers.NewAuthController(authService)
 	feedbackController := controllers.NewFeedbackController(feedbackService)
+	accountController := controllers.NewAccountController(accountService)
+	commentController := controllers.NewCommentController(commentService)
+	boardController := controllers.NewBoardController(boardService)
+	releaseController := controllers.NewReleaseController(releaseService)
+	widgetController := controllers.NewWidgetController(siteService, feedbackService, cfg.AppURL)
+	healthController := controllers.NewHealthController(healthService)
+	notificationController := controllers.NewNotificationController(notificationService)
+	inboundEmailController := controllers.NewInboundEmailController(inboundEmailService, cfg.InboundEmail.Token)
+	adminController := controllers.NewAdminController(
+		adminService,
+		authService,
+		boardService,
+		releaseService,
+		siteService,
+		time.Duration(cfg.JWTTokenExpireMinutes)*time.Minute,
+		cfg.AppEnv == "production",
+	)
+
+	apiSpec, err := openapi.Load()
+	if err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI specification: %w", err)
+	}
+	specHandler, err := openapi.SpecHandler(apiSpec)
+	if err != nil {
+		return nil, fmt.Errorf("encode OpenAPI specification: %w", err)
+	}
+
+	pages, err := template.ParseFS(templates.FS, "admin/*.html")
+	if err != nil {
+		return nil, fmt.Errorf("invalid page templates: %w", err)
+	}
 
 	r := gin.Default()
+	// Only X-Forwarded-For set by a trusted proxy is believed; otherwise
+	// clients could pick the IP the rate limiters key on.
+	if err := r.SetTrustedProxies(cfg.Security.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("trusted proxies: %w", err)
+	}
+	r.SetHTMLTemplate(pages)
+	r.Use(middleware.SecurityHeaders(middleware.SecurityHeadersConfig{
+		HSTSMaxAge:            time.Duration(cfg.Security.HSTSMaxAgeSeconds) * time.Second,
+		HSTSIncludeSubdomains: cfg.Security.HSTSIncludeSubdomains,
+		ReferrerPolicy:        cfg.Security.ReferrerPolicy,
+		ContentSecurityPolicy: cfg.Security.APIContentSecurityPolicy,
+	}))
+
+	r.Use(middleware.ErrorHandler())
+
+	// Operations that require authentication are validated after their
+	// group's authentication, so unauthenticated requests get 401, not 400.
+	validateAuthenticated := func(c *gin.Context) { c.Next() }
+	if cfg.OpenAPIValidate {
+		validator, err := openapi.NewValidator(apiSpec)
+		if err != nil {
+			return nil, fmt.Errorf("build OpenAPI validator: %w", err)
+		}
+		r.Use(validator.Middleware())
+		validateAuthenticated = validator.Authenticated()
+	}
+
+	r.GET("/openapi.json", specHandler)
+	r.GET("/docs", openapi.DocsHandler)
+	r.GET("/healthz", healthController.Live)
+	r.GET("/readyz", healthController.Ready)
+
+	// JSON routes keep the API policy from SecurityHeaders; HTML pages get
+	// pageCSP. Public JSON routes share the CORS settings of /api.
+	pageCSP := middleware.ContentSecurityPolicy(cfg.Security.PageContentSecurityPolicy)
+	corsMaxAge := time.Duration(cfg.Security.CORSMaxAgeSeconds) * time.Second
+	authCORS := corsPolicy(cfg.Security.AuthCORS, corsMaxAge)
+	apiCORS := corsPolicy(cfg.Security.APICORS, corsMaxAge)
+	adminCORS := corsPolicy(cfg.Security.AdminCORS, corsMaxAge)
 
 	loginRateLimiter := middleware.NewRateLimiter(time.Duration(cfg.RateLimitSeconds) * time.Second)
 
 	auth := r.Group("/auth")
+	auth.Use(authCORS)
 	{
+		auth.OPTIONS("/*path", middleware.Preflight)
 		auth.POST("/login", loginRateLimiter.Limit(), authController.RequestLogin)
 		auth.GET("/verify", authController.VerifyLogin)
 		auth.POST("/session", authController.CreateSession)
 	}
 
+	board := r.Group("/board")
+	board.Use(apiCORS)
+	{
+		board.OPTIONS("/*path", middleware.Preflight)
+		board.GET("/roadmap", boardController.Roadmap)
+		board.GET("/posts", boardController.List)
+		board.GET("/posts/:id", boardController.Get)
+		board.GET("/posts/:id/comments", boardController.Comments)
+	}
+
+	r.GET("/changelog", apiCORS, releaseController.Changelog)
+	r.OPTIONS("/changelog", apiCORS, middleware.Preflight)
+
+	if cfg.Guest.Enabled {
+		guestRateLimiter := middleware.NewRateLimiter(time.Duration(cfg.Guest.RateLimitSeconds) * time.Second)
+
+		guest := r.Group("/guest")
+		guest.Use(apiCORS)
+		{
+			guest.OPTIONS("/*path", middleware.Preflight)
+			guest.GET("/challenge", feedbackController.Challenge)
+			guest.POST("/feedback", guestRateLimiter.Limit(), feedbackController.SubmitGuestFeedback)
+		}
+	}
+
+	// The widget works for sites registered by staff even when general guest
+	// feedback is off, with the same limits.
+	widgetRateLimiter := middleware.NewRateLimiter(time.Duration(cfg.Guest.RateLimitSeconds) * time.Second)
+
+	r.GET("/widget.js", widgetController.Script)
+	r.GET("/widget/frame.js", widgetController.FrameScript)
+	widget := r.Group("/widget/:key")
+	widget.Use(widgetController.Site())
+	{
+		widget.GET("/frame", pageCSP, widgetController.Frame)
+		widget.GET("/challenge", widgetController.Challenge)
+		widget.OPTIONS("/challenge", widgetController.Preflight)
+		widget.POST("/feedback", widgetRateLimiter.Limit(), widgetController.Submit)
+		widget.OPTIONS("/feedback", widgetController.Preflight)
+	}
+
+	commentRateLimiter := middleware.NewRateLimiter(time.Duration(cfg.RateLimitSeconds) * time.Second)
+
 	api := r.Group("/api")
-	api.Use(middleware.AuthMiddleware(cfg.JWTSecret))
+	api.Use(apiCORS, middleware.AuthMiddleware(cfg.JWTSecret), validateAuthenticated)
 	{
+		api.OPTIONS("/*path", middleware.Preflight)
 		api.POST("/feedback", feedbackController.SubmitFeedback)
+		api.GET("/feedback/:id/comments", commentController.List)
+		api.POST("/feedback/:id/comments", commentController.Create)
+		api.PATCH("/feedback/:id/comments/:comment_id", commentController.Update)
+		api.POST("/board/posts/:id/vote", boardController.Vote)
+		api.DELETE("/board/posts/:id/vote", boardController.Unvote)
+		api.POST("/board/posts/:id/comments", commentRateLimiter.LimitUser(), boardController.Comment)
+		api.PUT("/board/posts/:id/subscription", boardController.Subscribe)
+		api.DELETE("/board/posts/:id/subscription", boardController.Unsubscribe)
+		api.GET("/board/me", boardController.Activity)
+		api.GET("/me", accountController.Me)
+		api.PATCH("/me", accountController.UpdateMe)
+	}
+
+	admin := r.Group("/admin")
+	admin.Use(adminCORS, pageCSP)
+	{
+		admin.OPTIONS("/*path", middleware.Preflight)
+		admin.GET("/login", adminController.LoginPage)
+		admin.POST("/login", loginRateLimiter.Limit(), adminController.RequestLogin)
+		admin.GET("/auth/verify", adminController.VerifyLogin)
+		admin.POST("/logout", adminController.Logout)
+	}
+
+	staff := admin.Group("")
+	staff.Use(middleware.AdminMiddleware(cfg.JWTSecret, userRepo), validateAuthenticated)
+	{
+		staff.GET("", adminController.Inbox)
+		staff.GET("/moderation", adminController.Moderation)
+		staff.GET("/feedback/:id", adminController.FeedbackDetail)
+		staff.POST("/feedback/:id/approve", adminController.Approve)
+		staff.POST("/feedback/:id/reject", adminController.Reject)
+		staff.POST("/feedback/:id/status", adminController.UpdateStatus)
+		staff.POST("/feedback/:id/tags", adminController.UpdateTags)
+		staff.POST("/feedback/:id/replies", adminController.Reply)
+		staff.POST("/feedback/:id/comments/:comment_id", adminController.EditComment)
+		staff.POST("/feedback/:id/publish", adminController.Publish)
+		staff.POST("/feedback/:id/unpublish", adminController.Unpublish)
+		staff.GET("/board", adminController.Board)
+		staff.POST("/board/comments/:id/delete", adminController.DeleteBoardComment)
+		staff.GET("/releases", adminController.Releases)
+		staff.POST("/releases", adminController.CreateRelease)
+		staff.GET("/releases/:id", adminController.Release)
+		staff.POST("/releases/:id", adminController.UpdateRelease)
+		staff.POST("/releases/:id/publish", adminController.PublishRelease)
+		staff.GET("/sites", adminController.Sites)
+		staff.POST("/sites", adminController.CreateSite)
+		staff.GET("/sites/:id", adminController.Site)
+		staff.POST("/sites/:id", adminController.UpdateSite)
+		staff.GET("/users", adminController.Users)
+		staff.GET("/charts", adminController.Charts)
+		staff.GET("/emails", adminController.Emails)
+		staff.POST("/emails/:id/retry", adminController.RetryEmail)
+	}
+
+	r.GET("/notifications/unsubscribe", pageCSP, notificationController.UnsubscribePage)
+	r.POST("/notifications/unsubscribe", pageCSP, notificationController.Unsubscribe)
+
+	if cfg.InboundEmail.Domain != "" {
+		r.POST("/inbound/email", inboundEmailController.Authorize, validateAuthenticated, inboundEmailController.Receive)
 	}
 
-	log.Printf("Server starting on %s", cfg.ServerPort)
-	if err := r.Run(cfg.ServerPort); err != nil {
-		log.Fatal("Server failed to start:", err)
+	if capture, ok := emailClient.(*email.CaptureSink); ok {
+		devEmailController := controllers.NewDevEmailController(capture)
+		r.GET("/dev/emails", devEmailController.List)
+		r.DELETE("/dev/emails", devEmailController.Clear)
 	}
+
+	return &server{router: r, emailQueue: emailQueue}, nil
+}
+
+// corsPolicy builds the CORS middleware of a route group from its settings.
+func corsPolicy(cors config.CORSConfig, maxAge time.Duration) gin.HandlerFunc {
+	return middleware.CORS(middleware.CORSConfig{
+		Origins:     cors.Origins,
+		Methods:     cors.Methods,
+		Headers:     cors.Headers,
+		Credentials: cors.Credentials,
+		MaxAge:      maxAge,
+	})
 }
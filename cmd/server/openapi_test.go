@@ -0,0 +1,476 @@
+package main
+
+import (
+	"bytes"
+	"feedback-app/config"
+	"feedback-app/db"
+	"feedback-app/middleware"
+	"feedback-app/models"
+	"feedback-app/openapi"
+	"feedback-app/platform/email"
+	"feedback-app/repository"
+	"feedback-app/services"
+	"feedback-app/templates"
+	"feedback-app/utils"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	testSecret       = "openapi-test-secret"
+	testAppURL       = "https://feedback.example.com"
+	testInboundToken = "inbound-token"
+	testReplyDomain  = "reply.example.com"
+	testSiteOrigin   = "https://shop.example.com"
+)
+
+// apiFixture is a server wired on an in-memory database, seeded with one of
+// everything the documented operations act on.
+type apiFixture struct {
+	router *gin.Engine
+	doc    *openapi3.T
+	routes routers.Router
+	db     *gorm.DB
+
+	admin, user   *models.User
+	userToken     string
+	adminCookie   string
+	feedback      *models.Feedback
+	pending       *models.Feedback
+	rejected      *models.Feedback
+	userComment   *models.Comment
+	staffComment  *models.Comment
+	post          *models.Post
+	postComment   *models.PostComment
+	release       *models.Release
+	site          *models.Site
+	failedEmail   *models.OutboundEmail
+	sessionToken  string
+	adminLogin    string
+	challenges    *services.ChallengeService
+	notifications *services.NotificationService
+}
+
+func newAPIFixture(t *testing.T) *apiFixture {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	gormDB, err := db.InitSQLite("file::memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		t.Fatalf("sqlite handle: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	emailFS, err := fs.Sub(templates.FS, "email")
+	if err != nil {
+		t.Fatalf("email templates: %v", err)
+	}
+	emailTemplates, err := email.NewTemplateRegistry(emailFS)
+	if err != nil {
+		t.Fatalf("email templates: %v", err)
+	}
+
+	cors := config.CORSConfig{
+		Origins: []string{testSiteOrigin},
+		Methods: []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+		Headers: []string{"Authorization", "Content-Type"},
+	}
+	cfg := &config.Config{
+		AppEnv:                 "test",
+		JWTSecret:              testSecret,
+		JWTTokenExpireMinutes:  60,
+		LoginLinkExpireMinutes: 15,
+		AppURL:                 testAppURL,
+		DeepLinkURL:            "feedbackapp://auth/callback",
+		OpenAPIValidate:        true,
+		EmailQueue:             config.EmailQueueConfig{Workers: 1, MaxAttempts: 3, RetryBaseSeconds: 1, PollSeconds: 1},
+		InboundEmail:           config.InboundEmailConfig{Domain: testReplyDomain, Token: testInboundToken},
+		Board:                  config.BoardConfig{VotesPerHour: 10, TrendingDays: 7},
+		Guest:                  config.GuestConfig{Enabled: true, ChallengeTTLMinutes: 10},
+		Security: config.SecurityConfig{
+			PageContentSecurityPolicy: "default-src 'self'",
+			AuthCORS:                  cors,
+			APICORS:                   cors,
+			AdminCORS:                 cors,
+		},
+	}
+
+	srv, err := newServer(cfg, gormDB, email.NewCaptureSink("noreply@example.com"), emailTemplates, 0)
+	if err != nil {
+		t.Fatalf("newServer: %v", err)
+	}
+
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("load OpenAPI document: %v", err)
+	}
+	routes, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		t.Fatalf("OpenAPI router: %v", err)
+	}
+
+	f := &apiFixture{
+		router:        srv.router,
+		doc:           doc,
+		routes:        routes,
+		db:            gormDB,
+		challenges:    services.NewChallengeService(repository.NewChallengeRepository(gormDB), testSecret, 0, 10*time.Minute),
+		notifications: services.NewNotificationService(nil, nil, nil, nil, nil, services.NotificationConfig{AppURL: testAppURL, Secret: testSecret}),
+	}
+	f.seed(t)
+	return f
+}
+
+func (f *apiFixture) create(t *testing.T, value interface{}) {
+	t.Helper()
+	if err := f.db.Create(value).Error; err != nil {
+		t.Fatalf("create %T: %v", value, err)
+	}
+}
+
+func (f *apiFixture) seed(t *testing.T) {
+	t.Helper()
+
+	longAgo := time.Now().Add(-30 * 24 * time.Hour)
+	f.admin = &models.User{Email: "staff@example.com", Role: models.RoleAdmin, CreatedAt: longAgo}
+	f.user = &models.User{Email: "ada@example.com", Role: models.RoleUser, CreatedAt: longAgo}
+	f.create(t, f.admin)
+	f.create(t, f.user)
+
+	var err error
+	if f.userToken, err = utils.GenerateJWT(f.user.ID, testSecret, time.Hour); err != nil {
+		t.Fatalf("user JWT: %v", err)
+	}
+	if f.adminCookie, err = utils.GenerateJWT(f.admin.ID, testSecret, time.Hour); err != nil {
+		t.Fatalf("admin JWT: %v", err)
+	}
+
+	f.feedback = &models.Feedback{UserID: &f.user.ID, Content: "Exports time out", Category: models.FeedbackCategoryBug}
+	f.pending = &models.Feedback{Content: "Add a dark theme", Moderation: models.ModerationPending}
+	f.rejected = &models.Feedback{Content: "Buy cheap watches", Moderation: models.ModerationPending}
+	f.create(t, f.feedback)
+	f.create(t, f.pending)
+	f.create(t, f.rejected)
+
+	f.userComment = &models.Comment{FeedbackID: f.feedback.ID, AuthorID: f.user.ID, Body: "It happens every time", Visibility: models.CommentVisibilityPublic}
+	f.staffComment = &models.Comment{FeedbackID: f.feedback.ID, AuthorID: f.admin.ID, Body: "Looking into it", Visibility: models.CommentVisibilityPublic}
+	f.create(t, f.userComment)
+	f.create(t, f.staffComment)
+
+	published := longAgo
+	f.post = &models.Post{FeedbackID: f.feedback.ID, Title: "Faster exports", Body: "Large exports time out.", PublishedAt: &published}
+	f.create(t, f.post)
+	f.postComment = &models.PostComment{PostID: f.post.ID, AuthorID: f.user.ID, Body: "Same here"}
+	f.create(t, f.postComment)
+
+	f.release = &models.Release{Version: "1.2.0", Title: "Faster exports", Notes: "Exports stream now."}
+	f.create(t, f.release)
+	f.site = &models.Site{Name: "Shop", PublicKey: "site_test", AllowedOrigins: testSiteOrigin}
+	f.create(t, f.site)
+	f.failedEmail = &models.OutboundEmail{Recipient: "ada@example.com", Subject: "Hello", Status: models.EmailStatusFailed, Attempts: 3, NextAttemptAt: time.Now()}
+	f.create(t, f.failedEmail)
+
+	f.sessionToken = "session-token"
+	f.adminLogin = "admin-login-token"
+	for token, userID := range map[string]uint{f.sessionToken: f.user.ID, f.adminLogin: f.admin.ID} {
+		f.create(t, &models.MagicLink{UserID: userID, Token: token, ExpiresAt: time.Now().Add(15 * time.Minute)})
+	}
+}
+
+// challenge returns a solved guest challenge as request fields.
+func (f *apiFixture) challenge(t *testing.T) string {
+	t.Helper()
+	challenge, err := f.challenges.Issue(time.Now())
+	if err != nil {
+		t.Fatalf("issue challenge: %v", err)
+	}
+	return fmt.Sprintf(`"challenge": %q, "solution": "0"`, challenge.Token)
+}
+
+// unsubscribeQuery returns the query of a signed unsubscribe link.
+func (f *apiFixture) unsubscribeQuery(userID uint, kind string) string {
+	link := f.notifications.UnsubscribeURL(userID, kind)
+	return link[strings.Index(link, "?"):]
+}
+
+// apiRequest is one request sent through the router. Auth adds the user's
+// bearer token, the admin session cookie or the inbound email token.
+type apiRequest struct {
+	method      string
+	path        string
+	contentType string
+	body        string
+	header      map[string]string
+	auth        string
+	want        int
+}
+
+const (
+	authUser    = "user"
+	authAdmin   = "admin"
+	authInbound = "inbound"
+)
+
+func jsonRequest(method string, path string, auth string, body string, want int) apiRequest {
+	return apiRequest{method: method, path: path, contentType: "application/json", body: body, auth: auth, want: want}
+}
+
+func formRequest(path string, form url.Values, want int) apiRequest {
+	return apiRequest{method: http.MethodPost, path: path, contentType: "application/x-www-form-urlencoded", body: form.Encode(), auth: authAdmin, want: want}
+}
+
+// do sends r through the router and checks the request and the response
+// against the operation it matches. It returns the matched operation.
+func (f *apiFixture) do(t *testing.T, r apiRequest) *openapi3.Operation {
+	t.Helper()
+
+	req := httptest.NewRequest(r.method, r.path, strings.NewReader(r.body))
+	if r.contentType != "" {
+		req.Header.Set("Content-Type", r.contentType)
+	}
+	for name, value := range r.header {
+		req.Header.Set(name, value)
+	}
+	switch r.auth {
+	case authUser:
+		req.Header.Set("Authorization", "Bearer "+f.userToken)
+	case authAdmin:
+		req.AddCookie(&http.Cookie{Name: middleware.AdminSessionCookie, Value: f.adminCookie})
+	case authInbound:
+		req.Header.Set("Authorization", "Bearer "+testInboundToken)
+	}
+
+	route, pathParams, err := f.routes.FindRoute(req)
+	if err != nil {
+		t.Errorf("%s %s is not documented: %v", r.method, r.path, err)
+		return nil
+	}
+	input := &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: pathParams,
+		Route:      route,
+		Options:    &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc},
+	}
+	if err := openapi3filter.ValidateRequest(req.Context(), input); err != nil {
+		t.Errorf("%s %s: request does not match the document: %v", r.method, r.path, err)
+		return route.Operation
+	}
+
+	rec := httptest.NewRecorder()
+	f.router.ServeHTTP(rec, req)
+
+	if rec.Code != r.want {
+		t.Errorf("%s %s: status %d, want %d; body: %s", r.method, r.path, rec.Code, r.want, rec.Body.String())
+	}
+	err = openapi3filter.ValidateResponse(req.Context(), &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 rec.Code,
+		Header:                 rec.Header(),
+		Body:                   io.NopCloser(bytes.NewReader(rec.Body.Bytes())),
+	})
+	if err != nil {
+		t.Errorf("%s %s: response %d does not match the document: %v", r.method, r.path, rec.Code, err)
+	}
+	return route.Operation
+}
+
+// TestOpenAPIOperations sends a request for every documented operation
+// through the router and checks both sides against the document.
+func TestOpenAPIOperations(t *testing.T) {
+	f := newAPIFixture(t)
+
+	feedback := fmt.Sprintf("/feedback/%d", f.feedback.ID)
+	post := fmt.Sprintf("/posts/%d", f.post.ID)
+	release := fmt.Sprintf("/admin/releases/%d", f.release.ID)
+	site := fmt.Sprintf("/admin/sites/%d", f.site.ID)
+	widget := "/widget/" + f.site.PublicKey
+	unsubscribe := "/notifications/unsubscribe" + f.unsubscribeQuery(f.user.ID, services.NotifyReplies)
+	preflight := map[string]string{"Origin": testSiteOrigin, "Access-Control-Request-Method": "POST"}
+	inbound := strings.Join([]string{
+		"From: Ada <ada@example.com>",
+		"To: " + services.NewReplyAddresses(testSecret, testReplyDomain).For(f.feedback.ID, f.user.ID),
+		"Subject: Re: Exports time out",
+		"Content-Type: text/plain; charset=utf-8",
+		"",
+		"Thanks, exports work again.",
+	}, "\r\n")
+
+	requests := []apiRequest{
+		{method: http.MethodGet, path: "/healthz", want: http.StatusOK},
+		{method: http.MethodGet, path: "/readyz", want: http.StatusOK},
+		{method: http.MethodGet, path: "/openapi.json", want: http.StatusOK},
+		{method: http.MethodGet, path: "/docs", want: http.StatusOK},
+
+		jsonRequest(http.MethodPost, "/auth/login", "", `{"email": "grace@example.com"}`, http.StatusOK),
+		{method: http.MethodGet, path: "/auth/verify?token=abc", want: http.StatusFound},
+		jsonRequest(http.MethodPost, "/auth/session", "", fmt.Sprintf(`{"token": %q}`, f.sessionToken), http.StatusOK),
+
+		{method: http.MethodGet, path: "/guest/challenge", want: http.StatusOK},
+		jsonRequest(http.MethodPost, "/guest/feedback", "", `{"content": "Love it", "email": "guest@example.com", `+f.challenge(t)+`}`, http.StatusAccepted),
+		{method: http.MethodGet, path: "/widget.js", want: http.StatusOK},
+		{method: http.MethodGet, path: "/widget/frame.js", want: http.StatusOK},
+		{method: http.MethodGet, path: widget + "/frame?page=https%3A%2F%2Fshop.example.com%2Fcart", want: http.StatusOK},
+		{method: http.MethodGet, path: widget + "/challenge", want: http.StatusOK},
+		{method: http.MethodOptions, path: widget + "/challenge", header: preflight, want: http.StatusNoContent},
+		jsonRequest(http.MethodPost, widget+"/feedback", "", `{"content": "Checkout is slow", `+f.challenge(t)+`}`, http.StatusAccepted),
+		{method: http.MethodOptions, path: widget + "/feedback", header: preflight, want: http.StatusNoContent},
+
+		{method: http.MethodGet, path: "/board/roadmap", want: http.StatusOK},
+		{method: http.MethodGet, path: "/changelog?limit=5", want: http.StatusOK},
+		{method: http.MethodGet, path: "/board/posts?sort=trending", want: http.StatusOK},
+		{method: http.MethodGet, path: "/board" + post, want: http.StatusOK},
+		{method: http.MethodGet, path: "/board" + post + "/comments", want: http.StatusOK},
+
+		jsonRequest(http.MethodPost, "/api/feedback", authUser, `{"content": "Please add CSV export", "category": "feature_request"}`, http.StatusCreated),
+		{method: http.MethodGet, path: "/api" + feedback + "/comments", auth: authUser, want: http.StatusOK},
+		jsonRequest(http.MethodPost, "/api"+feedback+"/comments", authUser, `{"body": "Still broken"}`, http.StatusCreated),
+		jsonRequest(http.MethodPatch, fmt.Sprintf("/api%s/comments/%d", feedback, f.userComment.ID), authUser, `{"body": "It happens on every export"}`, http.StatusOK),
+		{method: http.MethodGet, path: "/api/me", auth: authUser, want: http.StatusOK},
+		jsonRequest(http.MethodPatch, "/api/me", authUser, `{"notify_on_status": false}`, http.StatusOK),
+		{method: http.MethodPost, path: "/api/board" + post + "/vote", auth: authUser, want: http.StatusOK},
+		{method: http.MethodDelete, path: "/api/board" + post + "/vote", auth: authUser, want: http.StatusOK},
+		jsonRequest(http.MethodPost, "/api/board"+post+"/comments", authUser, `{"body": "+1"}`, http.StatusCreated),
+		{method: http.MethodPut, path: "/api/board" + post + "/subscription", auth: authUser, want: http.StatusNoContent},
+		{method: http.MethodDelete, path: "/api/board" + post + "/subscription", auth: authUser, want: http.StatusNoContent},
+		{method: http.MethodGet, path: "/api/board/me", auth: authUser, want: http.StatusOK},
+
+		{method: http.MethodGet, path: "/dev/emails?to=ada%40example.com", want: http.StatusOK},
+		{method: http.MethodDelete, path: "/dev/emails", want: http.StatusNoContent},
+		{method: http.MethodGet, path: unsubscribe, want: http.StatusOK},
+		{method: http.MethodPost, path: unsubscribe, contentType: "application/x-www-form-urlencoded", body: "List-Unsubscribe=One-Click", want: http.StatusOK},
+		{method: http.MethodPost, path: "/inbound/email", contentType: "message/rfc822", body: inbound, auth: authInbound, want: http.StatusCreated},
+
+		{method: http.MethodGet, path: "/admin/login", want: http.StatusOK},
+		{method: http.MethodPost, path: "/admin/login", contentType: "application/x-www-form-urlencoded", body: "email=staff%40example.com", want: http.StatusOK},
+		{method: http.MethodGet, path: "/admin/auth/verify?token=" + f.adminLogin, want: http.StatusFound},
+		{method: http.MethodPost, path: "/admin/logout", want: http.StatusFound},
+
+		{method: http.MethodGet, path: "/admin?status=new&page=1", auth: authAdmin, want: http.StatusOK},
+		{method: http.MethodGet, path: "/admin/moderation", auth: authAdmin, want: http.StatusOK},
+		{method: http.MethodGet, path: "/admin" + feedback, auth: authAdmin, want: http.StatusOK},
+		{method: http.MethodPost, path: fmt.Sprintf("/admin/feedback/%d/approve", f.pending.ID), auth: authAdmin, want: http.StatusSeeOther},
+		{method: http.MethodPost, path: fmt.Sprintf("/admin/feedback/%d/reject", f.rejected.ID), auth: authAdmin, want: http.StatusSeeOther},
+		formRequest("/admin"+feedback+"/status", url.Values{"status": {models.FeedbackStatusPlanned}}, http.StatusSeeOther),
+		formRequest("/admin"+feedback+"/tags", url.Values{"tags": {"export, performance"}}, http.StatusSeeOther),
+		formRequest("/admin"+feedback+"/replies", url.Values{"body": {"Fixed in 1.2"}, "visibility": {"public"}}, http.StatusSeeOther),
+		formRequest(fmt.Sprintf("/admin%s/comments/%d", feedback, f.staffComment.ID), url.Values{"body": {"Looking into it now"}}, http.StatusSeeOther),
+		formRequest("/admin"+feedback+"/publish", url.Values{"title": {"Faster exports"}, "body": {"Large exports no longer time out."}}, http.StatusSeeOther),
+		{method: http.MethodGet, path: "/admin/board", auth: authAdmin, want: http.StatusOK},
+		{method: http.MethodPost, path: fmt.Sprintf("/admin/board/comments/%d/delete", f.postComment.ID), auth: authAdmin, want: http.StatusSeeOther},
+
+		{method: http.MethodGet, path: "/admin/releases", auth: authAdmin, want: http.StatusOK},
+		formRequest("/admin/releases", url.Values{"version": {"1.3.0"}, "title": {"Next"}}, http.StatusSeeOther),
+		{method: http.MethodGet, path: release, auth: authAdmin, want: http.StatusOK},
+		formRequest(release, url.Values{"version": {"1.2.0"}, "title": {"Faster exports"}, "notes": {"Exports stream now."}, "feedback": {fmt.Sprint(f.feedback.ID)}}, http.StatusSeeOther),
+		{method: http.MethodPost, path: release + "/publish", auth: authAdmin, want: http.StatusSeeOther},
+		{method: http.MethodPost, path: release + "/publish", auth: authAdmin, want: http.StatusConflict},
+		{method: http.MethodPost, path: "/admin" + feedback + "/unpublish", auth: authAdmin, want: http.StatusSeeOther},
+
+		{method: http.MethodGet, path: "/admin/sites", auth: authAdmin, want: http.StatusOK},
+		formRequest("/admin/sites", url.Values{"name": {"Docs"}, "origins": {"https://docs.example.com"}}, http.StatusSeeOther),
+		{method: http.MethodGet, path: site, auth: authAdmin, want: http.StatusOK},
+		formRequest(site, url.Values{"name": {"Shop"}, "origins": {testSiteOrigin + " https://www.shop.example.com"}}, http.StatusSeeOther),
+
+		{method: http.MethodGet, path: "/admin/users?email=ada", auth: authAdmin, want: http.StatusOK},
+		{method: http.MethodGet, path: "/admin/charts", auth: authAdmin, want: http.StatusOK},
+		{method: http.MethodGet, path: "/admin/emails?status=failed", auth: authAdmin, want: http.StatusOK},
+		{method: http.MethodPost, path: fmt.Sprintf("/admin/emails/%d/retry", f.failedEmail.ID), auth: authAdmin, want: http.StatusSeeOther},
+	}
+
+	covered := make(map[*openapi3.Operation]bool)
+	for _, r := range requests {
+		if op := f.do(t, r); op != nil {
+			covered[op] = true
+		}
+	}
+
+	for path, item := range f.doc.Paths.Map() {
+		for method, op := range item.Operations() {
+			if !covered[op] {
+				t.Errorf("no request covers %s %s (%s)", method, path, op.OperationID)
+			}
+		}
+	}
+}
+
+// TestOpenAPIValidationAfterAuthentication checks that requests to secured
+// operations are authenticated before they are validated.
+func TestOpenAPIValidationAfterAuthentication(t *testing.T) {
+	f := newAPIFixture(t)
+
+	tests := []struct {
+		name string
+		req  apiRequest
+		want int
+	}{
+		{"bearer missing", apiRequest{method: http.MethodPost, path: "/api/feedback", contentType: "application/json", body: `{}`}, http.StatusUnauthorized},
+		{"bearer present", apiRequest{method: http.MethodPost, path: "/api/feedback", contentType: "application/json", body: `{}`, auth: authUser}, http.StatusBadRequest},
+		{"inbound token missing", apiRequest{method: http.MethodPost, path: "/inbound/email", contentType: "text/plain", body: "hello"}, http.StatusUnauthorized},
+		{"admin session missing", apiRequest{method: http.MethodPost, path: fmt.Sprintf("/admin/feedback/%d/status", f.feedback.ID), contentType: "application/x-www-form-urlencoded"}, http.StatusFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.req.method, tt.req.path, strings.NewReader(tt.req.body))
+			req.Header.Set("Content-Type", tt.req.contentType)
+			if tt.req.auth == authUser {
+				req.Header.Set("Authorization", "Bearer "+f.userToken)
+			}
+
+			rec := httptest.NewRecorder()
+			f.router.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status %d, want %d; body: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestUnsubscribePageTranslated(t *testing.T) {
+	f := newAPIFixture(t)
+
+	tests := []struct {
+		name   string
+		method string
+		query  string
+		want   int
+		text   string
+	}{
+		{"confirmation", http.MethodGet, f.unsubscribeQuery(f.user.ID, services.NotifyReplies), http.StatusOK, "<title>Abbestellen"},
+		{"unsubscribed", http.MethodPost, f.unsubscribeQuery(f.user.ID, services.NotifyReplies), http.StatusOK, "Du wurdest abgemeldet."},
+		{"invalid", http.MethodPost, fmt.Sprintf("?user=%d&kind=%s&sig=forged", f.user.ID, services.NotifyReplies), http.StatusBadRequest, "Dieser Abmeldelink ist ungültig."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/notifications/unsubscribe"+tt.query, nil)
+			req.Header.Set("Accept-Language", "de-DE,de;q=0.9")
+
+			rec := httptest.NewRecorder()
+			f.router.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status %d, want %d", rec.Code, tt.want)
+			}
+			if !strings.Contains(rec.Body.String(), tt.text) {
+				t.Errorf("body does not contain %q:\n%s", tt.text, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestWidgetOriginGate checks that only a site's allowed origins, and the
+// widget frame itself, can call the widget API.
+func TestWidgetOriginGate(t *testing.T) {
+	f := newAPIFixture(t)
+	widget := "/widget/" + f.site.PublicKey
+	submission := `{"content": "Love it", "email": "guest@example.com", ` + f.challenge(t) + `}`
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		origin      string
+		preflight   bool
+		body        string
+		want        int
+		allowOrigin string
+	}{
+		{"no origin", http.MethodGet, widget + "/challenge", "", false, "", http.StatusOK, ""},
+		{"allowed origin", http.MethodGet, widget + "/challenge", testSiteOrigin, false, "", http.StatusOK, testSiteOrigin},
+		{"allowed origin in other case", http.MethodGet, widget + "/challenge", strings.ToUpper(testSiteOrigin), false, "", http.StatusOK, strings.ToUpper(testSiteOrigin)},
+		{"frame origin", http.MethodGet, widget + "/challenge", testAppURL, false, "", http.StatusOK, ""},
+		{"unlisted origin", http.MethodGet, widget + "/challenge", "https://evil.example.com", false, "", http.StatusForbidden, ""},
+		{"unlisted port", http.MethodGet, widget + "/challenge", testSiteOrigin + ":8443", false, "", http.StatusForbidden, ""},
+		{"unlisted origin submits", http.MethodPost, widget + "/feedback", "https://evil.example.com", false, submission, http.StatusForbidden, ""},
+		{"allowed preflight", http.MethodOptions, widget + "/feedback", testSiteOrigin, true, "", http.StatusNoContent, testSiteOrigin},
+		{"unlisted preflight", http.MethodOptions, widget + "/feedback", "https://evil.example.com", true, "", http.StatusForbidden, ""},
+		{"unknown site", http.MethodGet, "/widget/unknown/challenge", testSiteOrigin, false, "", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+
+			rec := httptest.NewRecorder()
+			f.router.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status %d, want %d; body: %s", rec.Code, tt.want, rec.Body.String())
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.allowOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.allowOrigin)
+			}
+			if tt.want != http.StatusNotFound && !strings.Contains(strings.Join(rec.Header().Values("Vary"), ","), "Origin") {
+				t.Errorf("Vary = %q, want Origin", rec.Header().Values("Vary"))
+			}
+		})
+	}
+}
@@ -3,68 +3,258 @@ package config
 import (
 	"fmt"
 	"log"
+	"net/url"
 	"os"
-	"strconv"
 
 	"github.com/joho/godotenv"
 )
 
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+)
+
 type Config struct {
 	AppEnv                 string
 	ServerPort             string
+	DatabaseDriver         string
 	DatabaseDSN            string
+	DatabaseReplicaDSN     string
+	DBPool                 DBPoolConfig
+	MigrateOnStart         bool
+	MigrateLockTimeout     int
 	JWTSecret              string
 	JWTTokenExpireMinutes  int
 	LoginLinkExpireMinutes int
 	RateLimitSeconds       int
 	AppURL                 string
 	DeepLinkURL            string
+	OpenAPIValidate        bool
 	SMTP                   SMTPConfig
+	Email                  EmailConfig
+	EmailQueue             EmailQueueConfig
+	InboundEmail           InboundEmailConfig
+	PushEnabled            bool
+	Board                  BoardConfig
+	Guest                  GuestConfig
+	Security               SecurityConfig
+
+	settings []Setting
+}
+
+type DBPoolConfig struct {
+	MaxOpenConns           int
+	MaxIdleConns           int
+	ConnMaxLifetimeMinutes int
+	ConnMaxIdleTimeMinutes int
+	ConnectTimeoutSeconds  int
+}
+
+const (
+	EmailProviderSMTP    = "smtp"
+	EmailProviderHTTP    = "http"
+	EmailProviderFile    = "file"
+	EmailProviderConsole = "console"
+	EmailProviderCapture = "capture"
+)
+
+// EmailConfig selects how mail leaves the application. The file, console and
+// capture providers never deliver anything and are meant for development and
+// tests.
+type EmailConfig struct {
+	Provider          string
+	APIURL            string
+	APIKey            string
+	APITimeoutSeconds int
+	FileDir           string
+}
+
+type EmailQueueConfig struct {
+	Workers          int
+	MaxAttempts      int
+	RetryBaseSeconds int
+	PollSeconds      int
+}
+
+// InboundEmailConfig enables replying to notification emails. Replies are
+// addressed to reply+<thread>@Domain and posted to /inbound/email by the
+// receiving mail service, authenticated with Token.
+type InboundEmailConfig struct {
+	Domain string
+	Token  string
+}
+
+// BoardConfig sets how votes on the public board are counted.
+type BoardConfig struct {
+	VoteMinAccountAgeHours int
+	VotesPerHour           int
+	TrendingDays           int
+}
+
+// GuestConfig enables feedback from visitors without an account. Guests
+// must solve a proof-of-work challenge of Difficulty leading zero bits, and
+// their feedback waits in the moderation queue before it reaches the inbox.
+type GuestConfig struct {
+	Enabled             bool
+	Difficulty          int
+	ChallengeTTLMinutes int
+	RateLimitSeconds    int
+}
+
+// SecurityConfig sets the security headers sent with every response and
+// the CORS policy of each route group. JSON routes get APIContentSecurityPolicy
+// and HTML pages PageContentSecurityPolicy. HSTS is only sent over HTTPS.
+// X-Forwarded-For is only believed from TrustedProxies (IPs or CIDRs);
+// without any, the client IP is the address of the peer.
+type SecurityConfig struct {
+	HSTSMaxAgeSeconds         int
+	HSTSIncludeSubdomains     bool
+	ReferrerPolicy            string
+	APIContentSecurityPolicy  string
+	PageContentSecurityPolicy string
+	CORSMaxAgeSeconds         int
+	TrustedProxies            []string
+	AuthCORS                  CORSConfig
+	APICORS                   CORSConfig
+	AdminCORS                 CORSConfig
+}
+
+// CORSConfig is the cross-origin policy of a route group. No origins means
+// browsers on other origins cannot call it; "*" allows any origin, but not
+// together with Credentials.
+type CORSConfig struct {
+	Origins     []string
+	Methods     []string
+	Headers     []string
+	Credentials bool
 }
 
+const (
+	SMTPTLSNone     = "none"
+	SMTPTLSStartTLS = "starttls"
+	SMTPTLSImplicit = "tls"
+)
+
 type SMTPConfig struct {
 	Host     string
 	Port     string
 	User     string
 	Password string
 	From     string
+	// TLSMode is SMTPTLSNone, SMTPTLSStartTLS (required, usually port 587)
+	// or SMTPTLSImplicit (TLS from the first byte, usually port 465).
+	TLSMode            string
+	CAFile             string
+	InsecureSkipVerify bool
+	DialTimeoutSeconds int
+	SendTimeoutSeconds int
+	PoolSize           int
+	IdleTimeoutSeconds int
 }
 
+// LoadConfig resolves the configuration from the environment (and .env),
+// then the YAML or TOML file named by CONFIG_FILE, then built-in defaults.
 func LoadConfig() (*Config, error) {
 	err := godotenv.Load()
 	if err != nil {
 		log.Println("No .env file found, relying on environment variables")
 	}
 
-	dbUser := getEnv("DB_USER", "root")
-	dbPass := getEnv("DB_PASSWORD", "root")
-	dbHost := getEnv("DB_HOST", "127.0.0.1")
-	dbPort := getEnv("DB_PORT", "3306")
-	dbName := getEnv("DB_NAME", "feedback_app")
+	src, err := newSource(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		return nil, err
+	}
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local&multiStatements=true", dbUser, dbPass, dbHost, dbPort, dbName)
-	if val := os.Getenv("DATABASE_DSN"); val != "" {
+	dbDriver := src.getString("DB_DRIVER", DriverMySQL)
+	dsn := buildDSN(src, dbDriver)
+	if val := src.getString("DATABASE_DSN", ""); val != "" {
 		dsn = val
 	}
 
 	cfg := &Config{
-		AppEnv:                 getEnv("APP_ENV", "development"),
-		ServerPort:             getEnv("SERVER_PORT", ":8080"),
-		DatabaseDSN:            dsn,
-		JWTSecret:              getEnv("JWT_SECRET", "super-secret-key"),
-		JWTTokenExpireMinutes:  getEnvInt("JWT_TOKEN_EXPIRE_MINUTES", 120),
-		LoginLinkExpireMinutes: getEnvInt("LOGIN_LINK_EXPIRE_MINUTES", 15),
-		RateLimitSeconds:       getEnvInt("RATE_LIMIT", 5),
-		AppURL:                 getEnv("APP_URL", "http://localhost:8080"),
-		DeepLinkURL:            getEnv("DEEPLINK_URL", "exp://127.0.0.1:8081/--/auth/callback"),
+		AppEnv:             src.getString("APP_ENV", "development"),
+		ServerPort:         src.getString("SERVER_PORT", ":8080"),
+		DatabaseDriver:     dbDriver,
+		DatabaseDSN:        dsn,
+		DatabaseReplicaDSN: src.getString("DB_REPLICA_DSN", ""),
+		DBPool: DBPoolConfig{
+			MaxOpenConns:           src.getInt("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:           src.getInt("DB_MAX_IDLE_CONNS", 10),
+			ConnMaxLifetimeMinutes: src.getInt("DB_CONN_MAX_LIFETIME_MINUTES", 30),
+			ConnMaxIdleTimeMinutes: src.getInt("DB_CONN_MAX_IDLE_TIME_MINUTES", 5),
+			ConnectTimeoutSeconds:  src.getInt("DB_CONNECT_TIMEOUT_SECONDS", 60),
+		},
+		MigrateOnStart:         src.getBool("MIGRATE_ON_START", false),
+		MigrateLockTimeout:     src.getInt("MIGRATE_LOCK_TIMEOUT_SECONDS", 120),
+		JWTSecret:              src.getString("JWT_SECRET", "super-secret-key"),
+		JWTTokenExpireMinutes:  src.getInt("JWT_TOKEN_EXPIRE_MINUTES", 120),
+		LoginLinkExpireMinutes: src.getInt("LOGIN_LINK_EXPIRE_MINUTES", 15),
+		RateLimitSeconds:       src.getInt("RATE_LIMIT", 5),
+		AppURL:                 src.getString("APP_URL", "http://localhost:8080"),
+		DeepLinkURL:            src.getString("DEEPLINK_URL", "exp://127.0.0.1:8081/--/auth/callback"),
+		OpenAPIValidate:        src.getBool("OPENAPI_VALIDATE", false),
 		SMTP: SMTPConfig{
-			Host:     getEnv("SMTP_HOST", "localhost"),
-			Port:     getEnv("SMTP_PORT", "2525"),
-			User:     getEnv("SMTP_USER", ""),
-			Password: getEnv("SMTP_PASSWORD", ""),
-			From:     getEnv("SMTP_FROM", "noreply@feedback.app"),
+			Host:     src.getString("SMTP_HOST", "localhost"),
+			Port:     src.getString("SMTP_PORT", "2525"),
+			User:     src.getString("SMTP_USER", ""),
+			Password: src.getString("SMTP_PASSWORD", ""),
+			From:     src.getString("SMTP_FROM", "noreply@feedback.app"),
+
+			TLSMode:            src.getString("SMTP_TLS", SMTPTLSNone),
+			CAFile:             src.getString("SMTP_CA_FILE", ""),
+			InsecureSkipVerify: src.getBool("SMTP_TLS_INSECURE", false),
+			DialTimeoutSeconds: src.getInt("SMTP_DIAL_TIMEOUT_SECONDS", 10),
+			SendTimeoutSeconds: src.getInt("SMTP_SEND_TIMEOUT_SECONDS", 30),
+			PoolSize:           src.getInt("SMTP_POOL_SIZE", 4),
+			IdleTimeoutSeconds: src.getInt("SMTP_IDLE_TIMEOUT_SECONDS", 30),
+		},
+		Email: EmailConfig{
+			Provider:          src.getString("EMAIL_PROVIDER", EmailProviderSMTP),
+			APIURL:            src.getString("EMAIL_API_URL", ""),
+			APIKey:            src.getString("EMAIL_API_KEY", ""),
+			APITimeoutSeconds: src.getInt("EMAIL_API_TIMEOUT_SECONDS", 10),
+			FileDir:           src.getString("EMAIL_FILE_DIR", "tmp/emails"),
+		},
+		EmailQueue: EmailQueueConfig{
+			Workers:          src.getInt("EMAIL_QUEUE_WORKERS", 2),
+			MaxAttempts:      src.getInt("EMAIL_MAX_ATTEMPTS", 5),
+			RetryBaseSeconds: src.getInt("EMAIL_RETRY_BASE_SECONDS", 30),
+			PollSeconds:      src.getInt("EMAIL_QUEUE_POLL_SECONDS", 5),
+		},
+		InboundEmail: InboundEmailConfig{
+			Domain: src.getString("INBOUND_EMAIL_DOMAIN", ""),
+			Token:  src.getString("INBOUND_EMAIL_TOKEN", ""),
+		},
+		PushEnabled: src.getBool("PUSH_ENABLED", false),
+		Board: BoardConfig{
+			VoteMinAccountAgeHours: src.getInt("BOARD_VOTE_MIN_ACCOUNT_AGE_HOURS", 24),
+			VotesPerHour:           src.getInt("BOARD_VOTES_PER_HOUR", 30),
+			TrendingDays:           src.getInt("BOARD_TRENDING_DAYS", 7),
 		},
+		Guest: GuestConfig{
+			Enabled:             src.getBool("GUEST_FEEDBACK_ENABLED", false),
+			Difficulty:          src.getInt("GUEST_POW_DIFFICULTY", 20),
+			ChallengeTTLMinutes: src.getInt("GUEST_CHALLENGE_TTL_MINUTES", 10),
+			RateLimitSeconds:    src.getInt("GUEST_RATE_LIMIT", 60),
+		},
+		Security: SecurityConfig{
+			HSTSMaxAgeSeconds:         src.getInt("HSTS_MAX_AGE", 31536000),
+			HSTSIncludeSubdomains:     src.getBool("HSTS_INCLUDE_SUBDOMAINS", false),
+			ReferrerPolicy:            src.getString("REFERRER_POLICY", "strict-origin-when-cross-origin"),
+			APIContentSecurityPolicy:  src.getString("CSP_API", "default-src 'none'; frame-ancestors 'none'"),
+			PageContentSecurityPolicy: src.getString("CSP_PAGES", "default-src 'self'; style-src 'self' 'unsafe-inline'; img-src 'self' data:; form-action 'self'; base-uri 'none'; frame-ancestors 'none'"),
+			CORSMaxAgeSeconds:         src.getInt("CORS_MAX_AGE", 600),
+			TrustedProxies:            src.getList("TRUSTED_PROXIES", nil),
+			AuthCORS:                  corsConfig(src, "AUTH", []string{"GET", "POST"}, []string{"Content-Type", "Accept-Language"}),
+			APICORS:                   corsConfig(src, "API", []string{"GET", "POST", "PUT", "PATCH", "DELETE"}, []string{"Authorization", "Content-Type", "Accept-Language"}),
+			AdminCORS:                 corsConfig(src, "ADMIN", []string{"GET", "POST"}, []string{"Content-Type"}),
+		},
+	}
+
+	if err := src.err(); err != nil {
+		return nil, err
 	}
+	cfg.settings = src.sortedSettings()
 
 	if err := cfg.validate(); err != nil {
 		return nil, err
@@ -73,31 +263,50 @@ func LoadConfig() (*Config, error) {
 	return cfg, nil
 }
 
-func (c *Config) validate() error {
-	if c.JWTSecret == "" {
-		return fmt.Errorf("JWT_SECRET must be set")
-	}
-	if c.AppEnv == "production" && c.JWTSecret == "super-secret-key" {
-		return fmt.Errorf("JWT_SECRET must be set to a non-default value in production")
-	}
-	if c.RateLimitSeconds <= 0 {
-		return fmt.Errorf("RATE_LIMIT must be greater than zero")
+// Settings lists every configuration key with its effective value and
+// origin. With redact set, secrets are masked.
+func (c *Config) Settings(redact bool) []Setting {
+	settings := make([]Setting, len(c.settings))
+	copy(settings, c.settings)
+	if redact {
+		for i, setting := range settings {
+			if secretKeys[setting.Key] && setting.Value != "" {
+				settings[i].Value = redacted
+			}
+		}
 	}
-	return nil
+	return settings
 }
 
-func getEnv(key, fallback string) string {
-	if value, ok := os.LookupEnv(key); ok {
-		return value
+// corsConfig reads the CORS_<group>_* settings of a route group.
+func corsConfig(src *source, group string, methods []string, headers []string) CORSConfig {
+	prefix := "CORS_" + group + "_"
+	return CORSConfig{
+		Origins:     src.getList(prefix+"ORIGINS", nil),
+		Methods:     src.getList(prefix+"METHODS", methods),
+		Headers:     src.getList(prefix+"HEADERS", headers),
+		Credentials: src.getBool(prefix+"CREDENTIALS", false),
 	}
-	return fallback
 }
 
-func getEnvInt(key string, fallback int) int {
-	if value, ok := os.LookupEnv(key); ok {
-		if i, err := strconv.Atoi(value); err == nil {
-			return i
+// buildDSN assembles a connection string for driver from the DB_* variables.
+func buildDSN(src *source, driver string) string {
+	if driver == DriverPostgres {
+		u := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(src.getString("DB_USER", "postgres"), src.getString("DB_PASSWORD", "postgres")),
+			Host:     src.getString("DB_HOST", "127.0.0.1") + ":" + src.getString("DB_PORT", "5432"),
+			Path:     "/" + src.getString("DB_NAME", "feedback_app"),
+			RawQuery: "sslmode=" + url.QueryEscape(src.getString("DB_SSLMODE", "disable")),
 		}
+		return u.String()
 	}
-	return fallback
+
+	dbUser := src.getString("DB_USER", "root")
+	dbPass := src.getString("DB_PASSWORD", "root")
+	dbHost := src.getString("DB_HOST", "127.0.0.1")
+	dbPort := src.getString("DB_PORT", "3306")
+	dbName := src.getString("DB_NAME", "feedback_app")
+
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local&multiStatements=true", dbUser, dbPass, dbHost, dbPort, dbName)
 }
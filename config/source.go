@@ -0,0 +1,219 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// secretKeys are masked by Settings(true).
+var secretKeys = map[string]bool{
+	"JWT_SECRET":          true,
+	"SMTP_PASSWORD":       true,
+	"DB_PASSWORD":         true,
+	"DATABASE_DSN":        true,
+	"DB_REPLICA_DSN":      true,
+	"EMAIL_API_KEY":       true,
+	"INBOUND_EMAIL_TOKEN": true,
+}
+
+const redacted = "[redacted]"
+
+// Setting is one resolved configuration key and where its value came from.
+type Setting struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+// source resolves configuration keys from, in order of precedence, the
+// environment (including .env), the optional CONFIG_FILE and the defaults
+// given by the caller. For every KEY a KEY_FILE variant names a file holding
+// the value, which is how container secrets are usually mounted. Parse errors
+// are collected rather than silently replaced by the default.
+type source struct {
+	file     map[string]string
+	fileName string
+	used     map[string]bool
+	settings map[string]Setting
+	errs     []error
+}
+
+func newSource(configFile string) (*source, error) {
+	s := &source{
+		file:     map[string]string{},
+		used:     map[string]bool{},
+		settings: map[string]Setting{},
+	}
+	if configFile == "" {
+		return s, nil
+	}
+
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("read CONFIG_FILE: %w", err)
+	}
+
+	var tree map[string]interface{}
+	switch strings.ToLower(filepath.Ext(configFile)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &tree)
+	case ".toml":
+		err = toml.Unmarshal(content, &tree)
+	default:
+		return nil, fmt.Errorf("CONFIG_FILE must be .yaml, .yml or .toml, got %q", configFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", configFile, err)
+	}
+
+	flatten("", tree, s.file)
+	s.fileName = configFile
+	return s, nil
+}
+
+// flatten turns nested file sections into environment style keys, so that
+//
+//	db:
+//	  user: app
+//
+// and a top level `db_user: app` both set DB_USER. Lists become comma
+// separated values.
+func flatten(prefix string, node map[string]interface{}, out map[string]string) {
+	for rawKey, value := range node {
+		key := normalizeKey(rawKey)
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			flatten(key, v, out)
+		case []interface{}:
+			parts := make([]string, 0, len(v))
+			for _, item := range v {
+				parts = append(parts, fmt.Sprint(item))
+			}
+			out[key] = strings.Join(parts, ",")
+		case nil:
+			out[key] = ""
+		default:
+			out[key] = fmt.Sprint(v)
+		}
+	}
+}
+
+func normalizeKey(key string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(key))
+}
+
+// lookup resolves key, returning the value and a description of its origin.
+func (s *source) lookup(key string) (string, string, bool, error) {
+	s.used[key] = true
+	s.used[key+"_FILE"] = true
+
+	if value, ok := os.LookupEnv(key); ok {
+		return value, "env", true, nil
+	}
+	if path, ok := os.LookupEnv(key + "_FILE"); ok {
+		value, err := readSecretFile(key, path)
+		return value, "env " + key + "_FILE", true, err
+	}
+
+	if value, ok := s.file[key]; ok {
+		return value, s.fileName, true, nil
+	}
+	if path, ok := s.file[key+"_FILE"]; ok {
+		value, err := readSecretFile(key, path)
+		return value, s.fileName + " " + key + "_FILE", true, err
+	}
+	return "", "", false, nil
+}
+
+func readSecretFile(key string, path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("%s_FILE: %w", key, err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+func (s *source) getString(key, fallback string) string {
+	value, origin, ok, err := s.lookup(key)
+	if err != nil {
+		s.errs = append(s.errs, err)
+		ok = false
+	}
+	if !ok {
+		s.settings[key] = Setting{Key: key, Value: fallback, Source: "default"}
+		return fallback
+	}
+	s.settings[key] = Setting{Key: key, Value: value, Source: origin}
+	return value
+}
+
+func (s *source) getInt(key string, fallback int) int {
+	value := s.getString(key, strconv.Itoa(fallback))
+	i, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		s.errs = append(s.errs, fmt.Errorf("%s must be an integer, got %q", key, value))
+		return fallback
+	}
+	return i
+}
+
+func (s *source) getBool(key string, fallback bool) bool {
+	value := s.getString(key, strconv.FormatBool(fallback))
+	b, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		s.errs = append(s.errs, fmt.Errorf("%s must be true or false, got %q", key, value))
+		return fallback
+	}
+	return b
+}
+
+// getList reads a comma-separated list, dropping blank items. Lists in the
+// config file are joined with commas on load.
+func (s *source) getList(key string, fallback []string) []string {
+	value := s.getString(key, strings.Join(fallback, ","))
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// err reports parse errors and keys in the config file that no setting read,
+// which are almost always typos.
+func (s *source) err() error {
+	errs := s.errs
+	var unknown []string
+	for key := range s.file {
+		if !s.used[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		errs = append(errs, fmt.Errorf("unknown setting %s in %s", key, s.fileName))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *source) sortedSettings() []Setting {
+	settings := make([]Setting, 0, len(s.settings))
+	for _, setting := range s.settings {
+		settings = append(settings, setting)
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Key < settings[j].Key })
+	return settings
+}
@@ -0,0 +1,213 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+const minJWTSecretLength = 32
+
+const minInboundTokenLength = 16
+
+// maxGuestDifficulty caps the guest proof-of-work; every extra bit doubles
+// the work a browser has to do.
+const maxGuestDifficulty = 28
+
+// minJWTSecretEntropy is the minimum Shannon entropy per character of a
+// production JWT secret. Random hex scores about 3.5, base64 close to 5,
+// while repeated words and keyboard runs stay well below 3.
+const minJWTSecretEntropy = 3.0
+
+// weakJWTSecrets are placeholder values that must never reach production.
+var weakJWTSecrets = map[string]bool{
+	"super-secret-key": true,
+	"secret":           true,
+	"changeme":         true,
+	"change-me":        true,
+}
+
+// defaultDBCredentials are the user/password pairs the DSN falls back to.
+var defaultDBCredentials = map[string]string{
+	"root":     "root",
+	"postgres": "postgres",
+}
+
+// validate reports every configuration problem at once so an operator can
+// fix them in a single pass. Production adds checks that are too strict for
+// local development.
+func (c *Config) validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.DatabaseDriver == DriverMySQL || c.DatabaseDriver == DriverPostgres,
+		"DB_DRIVER must be %q or %q", DriverMySQL, DriverPostgres)
+	check(c.JWTSecret != "", "JWT_SECRET must be set")
+	check(c.DBPool.MaxOpenConns > 0, "DB_MAX_OPEN_CONNS must be greater than zero")
+	check(c.DBPool.MaxIdleConns >= 0, "DB_MAX_IDLE_CONNS must not be negative")
+	check(c.DBPool.ConnectTimeoutSeconds > 0, "DB_CONNECT_TIMEOUT_SECONDS must be greater than zero")
+	check(c.MigrateLockTimeout > 0, "MIGRATE_LOCK_TIMEOUT_SECONDS must be greater than zero")
+	check(c.JWTTokenExpireMinutes > 0, "JWT_TOKEN_EXPIRE_MINUTES must be greater than zero")
+	check(c.LoginLinkExpireMinutes > 0, "LOGIN_LINK_EXPIRE_MINUTES must be greater than zero")
+	check(c.RateLimitSeconds > 0, "RATE_LIMIT must be greater than zero")
+	for _, proxy := range c.Security.TrustedProxies {
+		_, _, cidrErr := net.ParseCIDR(proxy)
+		check(cidrErr == nil || net.ParseIP(proxy) != nil, "TRUSTED_PROXIES must list IPs or CIDRs, got %q", proxy)
+	}
+	check(c.SMTP.TLSMode == SMTPTLSNone || c.SMTP.TLSMode == SMTPTLSStartTLS || c.SMTP.TLSMode == SMTPTLSImplicit,
+		"SMTP_TLS must be %q, %q or %q", SMTPTLSNone, SMTPTLSStartTLS, SMTPTLSImplicit)
+	check(c.SMTP.DialTimeoutSeconds > 0, "SMTP_DIAL_TIMEOUT_SECONDS must be greater than zero")
+	check(c.SMTP.SendTimeoutSeconds > 0, "SMTP_SEND_TIMEOUT_SECONDS must be greater than zero")
+	check(c.SMTP.PoolSize > 0, "SMTP_POOL_SIZE must be greater than zero")
+	check(c.SMTP.IdleTimeoutSeconds >= 0, "SMTP_IDLE_TIMEOUT_SECONDS must not be negative")
+	switch c.Email.Provider {
+	case EmailProviderSMTP, EmailProviderFile, EmailProviderConsole, EmailProviderCapture:
+	case EmailProviderHTTP:
+		check(c.Email.APIURL != "", "EMAIL_API_URL must be set when EMAIL_PROVIDER is %q", EmailProviderHTTP)
+		check(c.Email.APITimeoutSeconds > 0, "EMAIL_API_TIMEOUT_SECONDS must be greater than zero")
+	default:
+		errs = append(errs, fmt.Errorf("EMAIL_PROVIDER must be one of %q, %q, %q, %q or %q, got %q",
+			EmailProviderSMTP, EmailProviderHTTP, EmailProviderFile, EmailProviderConsole, EmailProviderCapture, c.Email.Provider))
+	}
+	check(c.EmailQueue.Workers > 0, "EMAIL_QUEUE_WORKERS must be greater than zero")
+	check(c.EmailQueue.MaxAttempts > 0, "EMAIL_MAX_ATTEMPTS must be greater than zero")
+	check(c.EmailQueue.RetryBaseSeconds > 0, "EMAIL_RETRY_BASE_SECONDS must be greater than zero")
+	check(c.EmailQueue.PollSeconds > 0, "EMAIL_QUEUE_POLL_SECONDS must be greater than zero")
+	check(c.InboundEmail.Domain == "" || len(c.InboundEmail.Token) >= minInboundTokenLength,
+		"INBOUND_EMAIL_TOKEN must be at least %d characters when INBOUND_EMAIL_DOMAIN is set", minInboundTokenLength)
+	check(!strings.ContainsAny(c.InboundEmail.Domain, "@ "), "INBOUND_EMAIL_DOMAIN must be a bare domain, got %q", c.InboundEmail.Domain)
+	check(c.Board.VoteMinAccountAgeHours >= 0, "BOARD_VOTE_MIN_ACCOUNT_AGE_HOURS must not be negative")
+	check(c.Board.VotesPerHour > 0, "BOARD_VOTES_PER_HOUR must be greater than zero")
+	check(c.Board.TrendingDays > 0, "BOARD_TRENDING_DAYS must be greater than zero")
+	check(c.Guest.Difficulty >= 0 && c.Guest.Difficulty <= maxGuestDifficulty,
+		"GUEST_POW_DIFFICULTY must be between 0 and %d", maxGuestDifficulty)
+	check(c.Guest.ChallengeTTLMinutes > 0, "GUEST_CHALLENGE_TTL_MINUTES must be greater than zero")
+	check(c.Guest.RateLimitSeconds > 0, "GUEST_RATE_LIMIT must be greater than zero")
+	check(c.Security.HSTSMaxAgeSeconds >= 0, "HSTS_MAX_AGE must not be negative")
+	check(c.Security.CORSMaxAgeSeconds >= 0, "CORS_MAX_AGE must not be negative")
+	errs = append(errs, validateCORS("AUTH", c.Security.AuthCORS)...)
+	errs = append(errs, validateCORS("API", c.Security.APICORS)...)
+	errs = append(errs, validateCORS("ADMIN", c.Security.AdminCORS)...)
+
+	if c.AppEnv == "production" {
+		errs = append(errs, c.validateProduction()...)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateCORS checks the CORS_<group>_* settings. Origins must be given the
+// way browsers send them, such as https://app.example.com.
+func validateCORS(group string, cors CORSConfig) []error {
+	var errs []error
+	for _, origin := range cors.Origins {
+		if origin == "*" {
+			if cors.Credentials {
+				errs = append(errs, fmt.Errorf("CORS_%s_ORIGINS cannot be * when CORS_%s_CREDENTIALS is true", group, group))
+			}
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" || u.RawQuery != "" {
+			errs = append(errs, fmt.Errorf("CORS_%s_ORIGINS must list origins such as https://app.example.com, got %q", group, origin))
+		}
+	}
+	if len(cors.Origins) > 0 && len(cors.Methods) == 0 {
+		errs = append(errs, fmt.Errorf("CORS_%s_METHODS must not be empty when CORS_%s_ORIGINS is set", group, group))
+	}
+	return errs
+}
+
+func (c *Config) validateProduction() []error {
+	var errs []error
+
+	if weakJWTSecrets[strings.ToLower(c.JWTSecret)] {
+		errs = append(errs, errors.New("JWT_SECRET must be set to a non-default value in production"))
+	} else if len(c.JWTSecret) < minJWTSecretLength || shannonEntropy(c.JWTSecret) < minJWTSecretEntropy {
+		errs = append(errs, fmt.Errorf("JWT_SECRET must be at least %d random characters in production (e.g. openssl rand -hex 32)", minJWTSecretLength))
+	}
+
+	if u, err := url.Parse(c.AppURL); err != nil || u.Scheme != "https" || u.Host == "" {
+		errs = append(errs, fmt.Errorf("APP_URL must be an https:// URL in production, got %q", c.AppURL))
+	}
+
+	if u, err := url.Parse(c.DeepLinkURL); err != nil || u.Scheme == "" {
+		errs = append(errs, fmt.Errorf("DEEPLINK_URL must be an absolute URL, got %q", c.DeepLinkURL))
+	} else if u.Scheme == "exp" || u.Scheme == "exps" {
+		errs = append(errs, fmt.Errorf("DEEPLINK_URL must not be an Expo development link (%s://) in production", u.Scheme))
+	}
+
+	switch c.Email.Provider {
+	case EmailProviderSMTP:
+		if c.SMTP.User == "" || c.SMTP.Password == "" {
+			errs = append(errs, errors.New("SMTP_USER and SMTP_PASSWORD must be set in production"))
+		}
+		if c.SMTP.TLSMode == SMTPTLSNone {
+			errs = append(errs, fmt.Errorf("SMTP_TLS must be %q or %q in production", SMTPTLSStartTLS, SMTPTLSImplicit))
+		}
+		if c.SMTP.InsecureSkipVerify {
+			errs = append(errs, errors.New("SMTP_TLS_INSECURE must not be enabled in production"))
+		}
+	case EmailProviderHTTP:
+		if u, err := url.Parse(c.Email.APIURL); err != nil || u.Scheme != "https" {
+			errs = append(errs, errors.New("EMAIL_API_URL must be an https:// URL in production"))
+		}
+		if c.Email.APIKey == "" {
+			errs = append(errs, errors.New("EMAIL_API_KEY must be set in production"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("EMAIL_PROVIDER %q does not deliver email and cannot be used in production", c.Email.Provider))
+	}
+
+	if user, pass, ok := dsnCredentials(c.DatabaseDriver, c.DatabaseDSN); ok {
+		if want, isDefault := defaultDBCredentials[user]; isDefault && pass == want {
+			errs = append(errs, fmt.Errorf("database credentials must not be the default %s/%s in production", user, pass))
+		}
+	}
+
+	return errs
+}
+
+// dsnCredentials extracts the user and password from a connection string.
+func dsnCredentials(driver, dsn string) (string, string, bool) {
+	if driver == DriverPostgres {
+		u, err := url.Parse(dsn)
+		if err != nil || u.User == nil {
+			return "", "", false
+		}
+		pass, _ := u.User.Password()
+		return u.User.Username(), pass, true
+	}
+
+	parsed, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return "", "", false
+	}
+	return parsed.User, parsed.Passwd, true
+}
+
+// shannonEntropy returns the average number of bits per character of s.
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+
+	var entropy float64
+	for _, n := range counts {
+		p := float64(n) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"feedback-app/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccountController struct {
+	service *services.AccountService
+}
+
+func NewAccountController(service *services.AccountService) *AccountController {
+	return &AccountController{service: service}
+}
+
+type AccountUpdateRequest struct {
+	Locale         *string `json:"locale"`
+	NotifyOnReply  *bool   `json:"notify_on_reply"`
+	NotifyOnStatus *bool   `json:"notify_on_status"`
+}
+
+func (c *AccountController) Me(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.Error(services.Unauthorized("Invalid user context"))
+		return
+	}
+
+	user, err := c.service.Get(userID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+func (c *AccountController) UpdateMe(ctx *gin.Context) {
+	var req AccountUpdateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(services.ErrInvalidRequest)
+		return
+	}
+
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.Error(services.Unauthorized("Invalid user context"))
+		return
+	}
+
+	user, err := c.service.Update(userID, services.AccountUpdate{
+		Locale:         req.Locale,
+		NotifyOnReply:  req.NotifyOnReply,
+		NotifyOnStatus: req.NotifyOnStatus,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
@@ -0,0 +1,768 @@
+package controllers
+
+import (
+	"errors"
+	"feedback-app/middleware"
+	"feedback-app/models"
+	"feedback-app/repository"
+	"feedback-app/services"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	adminPageSize  = 50
+	adminChartDays = 30
+)
+
+type AdminController struct {
+	service       *services.AdminService
+	authService   *services.AuthService
+	boardService  *services.BoardService
+	releases      *services.ReleaseService
+	sites         *services.SiteService
+	sessionTTL    time.Duration
+	secureCookies bool
+}
+
+func NewAdminController(service *services.AdminService, authService *services.AuthService, boardService *services.BoardService, releases *services.ReleaseService, sites *services.SiteService, sessionTTL time.Duration, secureCookies bool) *AdminController {
+	return &AdminController{
+		service:       service,
+		authService:   authService,
+		boardService:  boardService,
+		releases:      releases,
+		sites:         sites,
+		sessionTTL:    sessionTTL,
+		secureCookies: secureCookies,
+	}
+}
+
+type chartBar struct {
+	Label   string
+	Count   int
+	Percent int
+}
+
+func (c *AdminController) LoginPage(ctx *gin.Context) {
+	ctx.HTML(http.StatusOK, "login.html", gin.H{
+		"Title": "Sign in",
+		"Error": ctx.Query("error"),
+	})
+}
+
+func (c *AdminController) RequestLogin(ctx *gin.Context) {
+	emailAddr := strings.TrimSpace(ctx.PostForm("email"))
+	if emailAddr == "" {
+		ctx.HTML(http.StatusBadRequest, "login.html", gin.H{"Title": "Sign in", "Error": "Email is required"})
+		return
+	}
+
+	if err := c.authService.RequestAdminLogin(emailAddr, middleware.RequestLocale(ctx)); err != nil {
+		log.Printf("Admin login request failed: %v", err)
+		ctx.HTML(http.StatusInternalServerError, "login.html", gin.H{"Title": "Sign in", "Error": "Failed to process login request"})
+		return
+	}
+
+	ctx.HTML(http.StatusOK, "login_sent.html", gin.H{"Title": "Check your email", "Email": emailAddr})
+}
+
+func (c *AdminController) VerifyLogin(ctx *gin.Context) {
+	token := ctx.Query("token")
+	if token == "" {
+		ctx.Redirect(http.StatusFound, "/admin/login?error="+url.QueryEscape("Token is required"))
+		return
+	}
+
+	jwtToken, err := c.authService.ExchangeLoginToken(token)
+	if err != nil {
+		ctx.Redirect(http.StatusFound, "/admin/login?error="+url.QueryEscape("Login link is invalid or has expired"))
+		return
+	}
+
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(middleware.AdminSessionCookie, jwtToken, int(c.sessionTTL.Seconds()), "/admin", "", c.secureCookies, true)
+	ctx.Redirect(http.StatusFound, "/admin")
+}
+
+func (c *AdminController) Logout(ctx *gin.Context) {
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(middleware.AdminSessionCookie, "", -1, "/admin", "", c.secureCookies, true)
+	ctx.Redirect(http.StatusFound, "/admin/login")
+}
+
+func (c *AdminController) Inbox(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+
+	filter := repository.FeedbackFilter{
+		Status:     ctx.Query("status"),
+		Category:   ctx.Query("category"),
+		Tag:        ctx.Query("tag"),
+		Query:      strings.TrimSpace(ctx.Query("q")),
+		Moderation: models.ModerationApproved,
+		Limit:      adminPageSize,
+		Offset:     (page - 1) * adminPageSize,
+	}
+	if from, err := time.ParseInLocation("2006-01-02", ctx.Query("from"), time.Local); err == nil {
+		filter.From = from
+	}
+	if to, err := time.ParseInLocation("2006-01-02", ctx.Query("to"), time.Local); err == nil {
+		filter.To = to.AddDate(0, 0, 1)
+	}
+
+	items, total, err := c.service.ListFeedback(filter)
+	if err != nil {
+		c.renderError(ctx, http.StatusInternalServerError, "Failed to load feedback")
+		return
+	}
+
+	tags, err := c.service.AllTags()
+	if err != nil {
+		c.renderError(ctx, http.StatusInternalServerError, "Failed to load tags")
+		return
+	}
+
+	pages := int(math.Ceil(float64(total) / float64(adminPageSize)))
+	ctx.HTML(http.StatusOK, "inbox.html", gin.H{
+		"Title":      "Inbox",
+		"Items":      items,
+		"Total":      total,
+		"Page":       page,
+		"Pages":      pages,
+		"PrevPage":   pageLink(ctx, page-1, page > 1),
+		"NextPage":   pageLink(ctx, page+1, page < pages),
+		"Statuses":   models.FeedbackStatuses,
+		"Categories": models.FeedbackCategories,
+		"Tags":       tags,
+		"Filter": gin.H{
+			"Status":   filter.Status,
+			"Category": filter.Category,
+			"Tag":      filter.Tag,
+			"Query":    filter.Query,
+			"From":     ctx.Query("from"),
+			"To":       ctx.Query("to"),
+		},
+	})
+}
+
+func (c *AdminController) FeedbackDetail(ctx *gin.Context) {
+	id, ok := idParam(ctx)
+	if !ok {
+		c.renderError(ctx, http.StatusNotFound, "Feedback not found")
+		return
+	}
+
+	feedback, err := c.service.GetFeedback(id)
+	if err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			c.renderError(ctx, http.StatusNotFound, "Feedback not found")
+			return
+		}
+		c.renderError(ctx, http.StatusInternalServerError, "Failed to load feedback")
+		return
+	}
+
+	post, err := c.boardService.PostForFeedback(feedback.ID)
+	if err != nil {
+		c.renderError(ctx, http.StatusInternalServerError, "Failed to load feedback")
+		return
+	}
+
+	staffID, _ := currentUserID(ctx)
+	tagNames := make([]string, 0, len(feedback.Tags))
+	for _, tag := range feedback.Tags {
+		tagNames = append(tagNames, tag.Name)
+	}
+
+	ctx.HTML(http.StatusOK, "feedback.html", gin.H{
+		"Title":    "Feedback #" + strconv.FormatUint(uint64(feedback.ID), 10),
+		"Feedback": feedback,
+		"TagList":  strings.Join(tagNames, ", "),
+		"Statuses": models.FeedbackStatuses,
+		"StaffID":  staffID,
+		"Post":     post,
+	})
+}
+
+// Moderation lists guest feedback waiting for approval.
+func (c *AdminController) Moderation(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+
+	items, total, err := c.service.ListFeedback(repository.FeedbackFilter{
+		Moderation: models.ModerationPending,
+		Limit:      adminPageSize,
+		Offset:     (page - 1) * adminPageSize,
+	})
+	if err != nil {
+		c.renderError(ctx, http.StatusInternalServerError, "Failed to load feedback")
+		return
+	}
+
+	pages := int(math.Ceil(float64(total) / float64(adminPageSize)))
+	ctx.HTML(http.StatusOK, "moderation.html", gin.H{
+		"Title":    "Moderation",
+		"Items":    items,
+		"Total":    total,
+		"Page":     page,
+		"Pages":    pages,
+		"PrevPage": pageLink(ctx, page-1, page > 1),
+		"NextPage": pageLink(ctx, page+1, page < pages),
+	})
+}
+
+func (c *AdminController) Approve(ctx *gin.Context) {
+	id, ok := idParam(ctx)
+	if !ok {
+		c.renderError(ctx, http.StatusNotFound, "Feedback not found")
+		return
+	}
+
+	if err := c.service.Approve(id); err != nil {
+		c.renderServiceError(ctx, err)
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, "/admin/moderation")
+}
+
+func (c *AdminController) Reject(ctx *gin.Context) {
+	id, ok := idParam(ctx)
+	if !ok {
+		c.renderError(ctx, http.StatusNotFound, "Feedback not found")
+		return
+	}
+
+	if err := c.service.Reject(id); err != nil {
+		c.renderServiceError(ctx, err)
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, "/admin/moderation")
+}
+
+func (c *AdminController) UpdateStatus(ctx *gin.Context) {
+	id, ok := idParam(ctx)
+	if !ok {
+		c.renderError(ctx, http.StatusNotFound, "Feedback not found")
+		return
+	}
+
+	if err := c.service.UpdateStatus(id, ctx.PostForm("status")); err != nil {
+		c.renderServiceError(ctx, err)
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, feedbackPath(id))
+}
+
+func (c *AdminController) UpdateTags(ctx *gin.Context) {
+	id, ok := idParam(ctx)
+	if !ok {
+		c.renderError(ctx, http.StatusNotFound, "Feedback not found")
+		return
+	}
+
+	if err := c.service.SetTags(id, ctx.PostForm("tags")); err != nil {
+		c.renderServiceError(ctx, err)
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, feedbackPath(id))
+}
+
+func (c *AdminController) Reply(ctx *gin.Context) {
+	id, ok := idParam(ctx)
+	if !ok {
+		c.renderError(ctx, http.StatusNotFound, "Feedback not found")
+		return
+	}
+
+	authorID, ok := currentUserID(ctx)
+	if !ok {
+		c.renderError(ctx, http.StatusForbidden, "Invalid user context")
+		return
+	}
+
+	if err := c.service.Reply(id, authorID, ctx.PostForm("body"), ctx.PostForm("visibility")); err != nil {
+		c.renderServiceError(ctx, err)
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, feedbackPath(id)+"#replies")
+}
+
+func (c *AdminController) EditComment(ctx *gin.Context) {
+	id, ok := idParam(ctx)
+	if !ok {
+		c.renderError(ctx, http.StatusNotFound, "Feedback not found")
+		return
+	}
+	commentID, ok := uintParam(ctx, "comment_id")
+	if !ok {
+		c.renderError(ctx, http.StatusNotFound, "Comment not found")
+		return
+	}
+
+	editorID, ok := currentUserID(ctx)
+	if !ok {
+		c.renderError(ctx, http.StatusForbidden, "Invalid user context")
+		return
+	}
+
+	if err := c.service.EditComment(id, commentID, editorID, ctx.PostForm("body")); err != nil {
+		c.renderServiceError(ctx, err)
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, feedbackPath(id)+"#comment-"+strconv.FormatUint(uint64(commentID), 10))
+}
+
+func (c *AdminController) Publish(ctx *gin.Context) {
+	id, ok := idParam(ctx)
+	if !ok {
+		c.renderError(ctx, http.StatusNotFound, "Feedback not found")
+		return
+	}
+
+	if err := c.boardService.Publish(id, ctx.PostForm("title"), ctx.PostForm("body")); err != nil {
+		c.renderServiceError(ctx, err)
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, feedbackPath(id)+"#board")
+}
+
+func (c *AdminController) Unpublish(ctx *gin.Context) {
+	id, ok := idParam(ctx)
+	if !ok {
+		c.renderError(ctx, http.StatusNotFound, "Feedback not found")
+		return
+	}
+
+	if err := c.boardService.Unpublish(id); err != nil {
+		c.renderServiceError(ctx, err)
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, feedbackPath(id)+"#board")
+}
+
+func (c *AdminController) Board(ctx *gin.Context) {
+	posts, err := c.boardService.AllPosts()
+	if err != nil {
+		c.renderError(ctx, http.StatusInternalServerError, "Failed to load board")
+		return
+	}
+
+	comments, err := c.boardService.RecentComments()
+	if err != nil {
+		c.renderError(ctx, http.StatusInternalServerError, "Failed to load board")
+		return
+	}
+
+	ctx.HTML(http.StatusOK, "board.html", gin.H{
+		"Title":    "Board",
+		"Posts":    posts,
+		"Comments": comments,
+	})
+}
+
+func (c *AdminController) DeleteBoardComment(ctx *gin.Context) {
+	id, ok := idParam(ctx)
+	if !ok {
+		c.renderError(ctx, http.StatusNotFound, "Comment not found")
+		return
+	}
+
+	if err := c.boardService.DeleteComment(id); err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			c.renderError(ctx, http.StatusNotFound, "Comment not found")
+			return
+		}
+		log.Printf("Admin board comment delete failed: %v", err)
+		c.renderError(ctx, http.StatusInternalServerError, "Failed to delete comment")
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, "/admin/board")
+}
+
+func (c *AdminController) Releases(ctx *gin.Context) {
+	releases, err := c.releases.All()
+	if err != nil {
+		c.renderError(ctx, http.StatusInternalServerError, "Failed to load releases")
+		return
+	}
+
+	ctx.HTML(http.StatusOK, "releases.html", gin.H{
+		"Title":    "Releases",
+		"Releases": releases,
+	})
+}
+
+func (c *AdminController) CreateRelease(ctx *gin.Context) {
+	release, err := c.releases.Create(ctx.PostForm("version"), ctx.PostForm("title"), ctx.PostForm("notes"))
+	if err != nil {
+		c.renderReleaseError(ctx, err)
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, releasePath(release.ID))
+}
+
+func (c *AdminController) Release(ctx *gin.Context) {
+	id, ok := idParam(ctx)
+	if !ok {
+		c.renderError(ctx, http.StatusNotFound, "Release not found")
+		return
+	}
+
+	release, err := c.releases.Get(id)
+	if err != nil {
+		c.renderReleaseError(ctx, err)
+		return
+	}
+
+	feedbackIDs := make([]string, 0, len(release.Feedback))
+	for _, feedback := range release.Feedback {
+		feedbackIDs = append(feedbackIDs, strconv.FormatUint(uint64(feedback.ID), 10))
+	}
+
+	ctx.HTML(http.StatusOK, "release.html", gin.H{
+		"Title":       release.Version,
+		"Release":     release,
+		"FeedbackIDs": strings.Join(feedbackIDs, ", "),
+	})
+}
+
+func (c *AdminController) UpdateRelease(ctx *gin.Context) {
+	id, ok := idParam(ctx)
+	if !ok {
+		c.renderError(ctx, http.StatusNotFound, "Release not found")
+		return
+	}
+
+	// Feedback is listed by ID, separated by commas or spaces, as in
+	// "12, #15".
+	var feedbackIDs []uint
+	for _, field := range strings.FieldsFunc(ctx.PostForm("feedback"), func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+		feedbackID, err := strconv.ParseUint(strings.TrimPrefix(field, "#"), 10, 64)
+		if err != nil || feedbackID == 0 {
+			c.renderError(ctx, http.StatusBadRequest, "Feedback must be listed by ID")
+			return
+		}
+		feedbackIDs = append(feedbackIDs, uint(feedbackID))
+	}
+
+	err := c.releases.Update(id, ctx.PostForm("version"), ctx.PostForm("title"), ctx.PostForm("notes"), feedbackIDs)
+	if err != nil {
+		c.renderReleaseError(ctx, err)
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, releasePath(id))
+}
+
+func (c *AdminController) PublishRelease(ctx *gin.Context) {
+	id, ok := idParam(ctx)
+	if !ok {
+		c.renderError(ctx, http.StatusNotFound, "Release not found")
+		return
+	}
+
+	if err := c.releases.Publish(id); err != nil {
+		c.renderReleaseError(ctx, err)
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, releasePath(id))
+}
+
+// Sites lists the sites that embed the feedback widget.
+func (c *AdminController) Sites(ctx *gin.Context) {
+	sites, err := c.sites.All()
+	if err != nil {
+		c.renderError(ctx, http.StatusInternalServerError, "Failed to load sites")
+		return
+	}
+
+	ctx.HTML(http.StatusOK, "sites.html", gin.H{
+		"Title": "Sites",
+		"Sites": sites,
+	})
+}
+
+func (c *AdminController) CreateSite(ctx *gin.Context) {
+	site, err := c.sites.Create(ctx.PostForm("name"), ctx.PostForm("origins"))
+	if err != nil {
+		c.renderSiteError(ctx, err)
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, sitePath(site.ID))
+}
+
+func (c *AdminController) Site(ctx *gin.Context) {
+	id, ok := idParam(ctx)
+	if !ok {
+		c.renderError(ctx, http.StatusNotFound, "Site not found")
+		return
+	}
+
+	site, err := c.sites.Get(id)
+	if err != nil {
+		c.renderSiteError(ctx, err)
+		return
+	}
+
+	ctx.HTML(http.StatusOK, "site.html", gin.H{
+		"Title":     site.Name,
+		"Site":      site,
+		"EmbedCode": c.sites.EmbedCode(site),
+	})
+}
+
+func (c *AdminController) UpdateSite(ctx *gin.Context) {
+	id, ok := idParam(ctx)
+	if !ok {
+		c.renderError(ctx, http.StatusNotFound, "Site not found")
+		return
+	}
+
+	if err := c.sites.Update(id, ctx.PostForm("name"), ctx.PostForm("origins")); err != nil {
+		c.renderSiteError(ctx, err)
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, sitePath(id))
+}
+
+func (c *AdminController) Users(ctx *gin.Context) {
+	query := strings.TrimSpace(ctx.Query("email"))
+
+	var results []services.UserLookup
+	if query != "" {
+		var err error
+		results, err = c.service.LookupUsers(query)
+		if err != nil {
+			c.renderError(ctx, http.StatusInternalServerError, "Failed to look up users")
+			return
+		}
+	}
+
+	ctx.HTML(http.StatusOK, "users.html", gin.H{
+		"Title":   "Users",
+		"Query":   query,
+		"Results": results,
+	})
+}
+
+func (c *AdminController) Charts(ctx *gin.Context) {
+	volume, err := c.service.VolumeByDay(adminChartDays, time.Now())
+	if err != nil {
+		c.renderError(ctx, http.StatusInternalServerError, "Failed to load chart data")
+		return
+	}
+
+	statusCounts, err := c.service.StatusCounts()
+	if err != nil {
+		c.renderError(ctx, http.StatusInternalServerError, "Failed to load chart data")
+		return
+	}
+
+	volumeMax := 0
+	for _, day := range volume {
+		if day.Count > volumeMax {
+			volumeMax = day.Count
+		}
+	}
+	volumeBars := make([]chartBar, 0, len(volume))
+	for _, day := range volume {
+		volumeBars = append(volumeBars, chartBar{
+			Label:   day.Day.Format("Jan 2"),
+			Count:   day.Count,
+			Percent: percentOf(int64(day.Count), int64(volumeMax)),
+		})
+	}
+
+	var statusMax int64
+	for _, count := range statusCounts {
+		if count > statusMax {
+			statusMax = count
+		}
+	}
+	statusBars := make([]chartBar, 0, len(models.FeedbackStatuses))
+	for _, status := range models.FeedbackStatuses {
+		statusBars = append(statusBars, chartBar{
+			Label:   status,
+			Count:   int(statusCounts[status]),
+			Percent: percentOf(statusCounts[status], statusMax),
+		})
+	}
+
+	ctx.HTML(http.StatusOK, "charts.html", gin.H{
+		"Title":      "Charts",
+		"Days":       adminChartDays,
+		"VolumeBars": volumeBars,
+		"StatusBars": statusBars,
+	})
+}
+
+func (c *AdminController) Emails(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	status := ctx.DefaultQuery("status", models.EmailStatusFailed)
+
+	items, total, err := c.service.ListEmails(status, adminPageSize, (page-1)*adminPageSize)
+	if err != nil {
+		c.renderError(ctx, http.StatusInternalServerError, "Failed to load emails")
+		return
+	}
+
+	counts, err := c.service.EmailCounts()
+	if err != nil {
+		c.renderError(ctx, http.StatusInternalServerError, "Failed to load emails")
+		return
+	}
+
+	pages := int(math.Ceil(float64(total) / float64(adminPageSize)))
+	ctx.HTML(http.StatusOK, "emails.html", gin.H{
+		"Title":    "Emails",
+		"Items":    items,
+		"Total":    total,
+		"Page":     page,
+		"Pages":    pages,
+		"PrevPage": pageLink(ctx, page-1, page > 1),
+		"NextPage": pageLink(ctx, page+1, page < pages),
+		"Status":   status,
+		"Statuses": models.EmailStatuses,
+		"Counts":   counts,
+	})
+}
+
+func (c *AdminController) RetryEmail(ctx *gin.Context) {
+	id, ok := idParam(ctx)
+	if !ok {
+		c.renderError(ctx, http.StatusNotFound, "Email not found")
+		return
+	}
+
+	if err := c.service.RetryEmail(id); err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			c.renderError(ctx, http.StatusNotFound, "Email not found, not failed or expired")
+			return
+		}
+		log.Printf("Admin email retry failed: %v", err)
+		c.renderError(ctx, http.StatusInternalServerError, "Failed to retry email")
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, "/admin/emails")
+}
+
+func (c *AdminController) renderServiceError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		c.renderError(ctx, http.StatusNotFound, "Feedback not found")
+	case errors.Is(err, services.ErrForbidden):
+		c.renderError(ctx, http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrInvalidStatus):
+		c.renderError(ctx, http.StatusBadRequest, "Invalid status")
+	case errors.Is(err, services.ErrInvalidRequest):
+		c.renderError(ctx, http.StatusBadRequest, err.Error())
+	default:
+		log.Printf("Admin action failed: %v", err)
+		c.renderError(ctx, http.StatusInternalServerError, "Failed to update feedback")
+	}
+}
+
+func (c *AdminController) renderReleaseError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		c.renderError(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrConflict):
+		c.renderError(ctx, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrInvalidRequest):
+		c.renderError(ctx, http.StatusBadRequest, err.Error())
+	default:
+		log.Printf("Admin release action failed: %v", err)
+		c.renderError(ctx, http.StatusInternalServerError, "Failed to update release")
+	}
+}
+
+func (c *AdminController) renderSiteError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		c.renderError(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrInvalidRequest):
+		c.renderError(ctx, http.StatusBadRequest, err.Error())
+	default:
+		log.Printf("Admin site action failed: %v", err)
+		c.renderError(ctx, http.StatusInternalServerError, "Failed to update site")
+	}
+}
+
+func (c *AdminController) renderError(ctx *gin.Context, status int, message string) {
+	ctx.HTML(status, "error.html", gin.H{
+		"Title":   "Error",
+		"Message": message,
+	})
+}
+
+func idParam(ctx *gin.Context) (uint, bool) {
+	return uintParam(ctx, "id")
+}
+
+// uintParam parses the positive integer path parameter name.
+func uintParam(ctx *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param(name), 10, 64)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func feedbackPath(id uint) string {
+	return "/admin/feedback/" + strconv.FormatUint(uint64(id), 10)
+}
+
+func releasePath(id uint) string {
+	return "/admin/releases/" + strconv.FormatUint(uint64(id), 10)
+}
+
+func sitePath(id uint) string {
+	return "/admin/sites/" + strconv.FormatUint(uint64(id), 10)
+}
+
+// pageLink rebuilds the current query string with a different page number, or
+// returns an empty string when the target page does not exist.
+func pageLink(ctx *gin.Context, page int, exists bool) string {
+	if !exists {
+		return ""
+	}
+	query := ctx.Request.URL.Query()
+	query.Set("page", strconv.Itoa(page))
+	return "?" + query.Encode()
+}
+
+func percentOf(value, max int64) int {
+	if max == 0 {
+		return 0
+	}
+	return int(value * 100 / max)
+}
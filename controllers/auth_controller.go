@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"feedback-app/i18n"
+	"feedback-app/middleware"
 	"feedback-app/services"
 	"net/http"
 
@@ -26,30 +28,31 @@ type VerifyTokenRequest struct {
 func (c *AuthController) RequestLogin(ctx *gin.Context) {
 	var req LoginRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email format"})
+		ctx.Error(services.Invalid("Invalid email format"))
 		return
 	}
 
-	if err := c.service.RequestLogin(req.Email); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process login request"})
+	locale := middleware.RequestLocale(ctx)
+	if err := c.service.RequestLogin(req.Email, locale); err != nil {
+		ctx.Error(err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "Please check your email for the login link",
+		"message": i18n.T(locale, "Please check your email for the login link"),
 	})
 }
 
 func (c *AuthController) VerifyLogin(ctx *gin.Context) {
 	token := ctx.Query("token")
 	if token == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Token is required"})
+		ctx.Error(services.Invalid("Token is required"))
 		return
 	}
 
 	redirectURL, err := c.service.BuildRedirectURL(token)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build redirect URL"})
+		ctx.Error(err)
 		return
 	}
 
@@ -59,13 +62,13 @@ func (c *AuthController) VerifyLogin(ctx *gin.Context) {
 func (c *AuthController) CreateSession(ctx *gin.Context) {
 	var req VerifyTokenRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Token is required"})
+		ctx.Error(services.Invalid("Token is required"))
 		return
 	}
 
 	jwtToken, err := c.service.ExchangeLoginToken(req.Token)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"feedback-app/models"
+	"feedback-app/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BoardController serves the public feature-request board. Reading is open
+// to everyone; voting, commenting and subscribing need a signed-in user.
+type BoardController struct {
+	service *services.BoardService
+}
+
+func NewBoardController(service *services.BoardService) *BoardController {
+	return &BoardController{service: service}
+}
+
+func (c *BoardController) List(ctx *gin.Context) {
+	limit, _ := strconv.Atoi(ctx.Query("limit"))
+	offset, _ := strconv.Atoi(ctx.Query("offset"))
+
+	posts, total, err := c.service.List(services.BoardQuery{
+		Sort:   ctx.Query("sort"),
+		Status: ctx.Query("status"),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"posts": posts, "total": total})
+}
+
+func (c *BoardController) Roadmap(ctx *gin.Context) {
+	roadmap, err := c.service.Roadmap()
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, roadmap)
+}
+
+func (c *BoardController) Get(ctx *gin.Context) {
+	postID, ok := uintParam(ctx, "id")
+	if !ok {
+		ctx.Error(services.NotFound("Post not found"))
+		return
+	}
+
+	post, err := c.service.Get(postID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, post)
+}
+
+func (c *BoardController) Comments(ctx *gin.Context) {
+	postID, ok := uintParam(ctx, "id")
+	if !ok {
+		ctx.Error(services.NotFound("Post not found"))
+		return
+	}
+
+	comments, err := c.service.Comments(postID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"comments": comments})
+}
+
+func (c *BoardController) Vote(ctx *gin.Context) {
+	c.votePost(ctx, c.service.Vote)
+}
+
+func (c *BoardController) Unvote(ctx *gin.Context) {
+	c.votePost(ctx, c.service.Unvote)
+}
+
+func (c *BoardController) votePost(ctx *gin.Context, action func(userID uint, postID uint) (*models.Post, error)) {
+	userID, postID, ok := c.userAndPost(ctx)
+	if !ok {
+		return
+	}
+
+	post, err := action(userID, postID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, post)
+}
+
+func (c *BoardController) Comment(ctx *gin.Context) {
+	var req CommentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(services.Invalid("Comment is required"))
+		return
+	}
+
+	userID, postID, ok := c.userAndPost(ctx)
+	if !ok {
+		return
+	}
+
+	comment, err := c.service.Comment(userID, postID, req.Body)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, comment)
+}
+
+func (c *BoardController) Subscribe(ctx *gin.Context) {
+	c.subscription(ctx, c.service.Subscribe)
+}
+
+func (c *BoardController) Unsubscribe(ctx *gin.Context) {
+	c.subscription(ctx, c.service.Unsubscribe)
+}
+
+func (c *BoardController) subscription(ctx *gin.Context, action func(userID uint, postID uint) error) {
+	userID, postID, ok := c.userAndPost(ctx)
+	if !ok {
+		return
+	}
+
+	if err := action(userID, postID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *BoardController) Activity(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.Error(services.Unauthorized("Invalid user context"))
+		return
+	}
+
+	activity, err := c.service.Activity(userID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, activity)
+}
+
+func (c *BoardController) userAndPost(ctx *gin.Context) (uint, uint, bool) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.Error(services.Unauthorized("Invalid user context"))
+		return 0, 0, false
+	}
+	postID, ok := uintParam(ctx, "id")
+	if !ok {
+		ctx.Error(services.NotFound("Post not found"))
+		return 0, 0, false
+	}
+	return userID, postID, true
+}
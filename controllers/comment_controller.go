@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"feedback-app/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CommentController struct {
+	service *services.CommentService
+}
+
+func NewCommentController(service *services.CommentService) *CommentController {
+	return &CommentController{service: service}
+}
+
+type CommentRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+func (c *CommentController) List(ctx *gin.Context) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.Error(services.Unauthorized("Invalid user context"))
+		return
+	}
+	feedbackID, ok := uintParam(ctx, "id")
+	if !ok {
+		ctx.Error(services.NotFound("Feedback not found"))
+		return
+	}
+
+	comments, err := c.service.List(userID, feedbackID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"comments": comments})
+}
+
+func (c *CommentController) Create(ctx *gin.Context) {
+	var req CommentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(services.Invalid("Comment is required"))
+		return
+	}
+
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.Error(services.Unauthorized("Invalid user context"))
+		return
+	}
+	feedbackID, ok := uintParam(ctx, "id")
+	if !ok {
+		ctx.Error(services.NotFound("Feedback not found"))
+		return
+	}
+
+	comment, err := c.service.Add(userID, feedbackID, req.Body)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, comment)
+}
+
+func (c *CommentController) Update(ctx *gin.Context) {
+	var req CommentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(services.Invalid("Comment is required"))
+		return
+	}
+
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.Error(services.Unauthorized("Invalid user context"))
+		return
+	}
+	feedbackID, ok := uintParam(ctx, "id")
+	if !ok {
+		ctx.Error(services.NotFound("Feedback not found"))
+		return
+	}
+	commentID, ok := uintParam(ctx, "comment_id")
+	if !ok {
+		ctx.Error(services.NotFound("Comment not found"))
+		return
+	}
+
+	comment, err := c.service.Edit(userID, feedbackID, commentID, req.Body)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, comment)
+}
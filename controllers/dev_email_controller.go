@@ -0,0 +1,28 @@
+package controllers
+
+import (
+	"feedback-app/platform/email"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DevEmailController exposes the messages held by the capture email provider
+// so tests can fetch login links. It is only routed when EMAIL_PROVIDER is
+// capture, which production configuration rejects.
+type DevEmailController struct {
+	sink *email.CaptureSink
+}
+
+func NewDevEmailController(sink *email.CaptureSink) *DevEmailController {
+	return &DevEmailController{sink: sink}
+}
+
+func (c *DevEmailController) List(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"emails": c.sink.Messages(ctx.Query("to"))})
+}
+
+func (c *DevEmailController) Clear(ctx *gin.Context) {
+	c.sink.Reset()
+	ctx.Status(http.StatusNoContent)
+}
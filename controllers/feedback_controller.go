@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"feedback-app/i18n"
+	"feedback-app/middleware"
 	"feedback-app/services"
 	"net/http"
 
@@ -16,35 +18,89 @@ func NewFeedbackController(service *services.FeedbackService) *FeedbackControlle
 }
 
 type FeedbackRequest struct {
-	Content string `json:"content" binding:"required"`
+	Content  string            `json:"content" binding:"required"`
+	Category string            `json:"category"`
+	Metadata map[string]string `json:"metadata"`
 }
 
 func (c *FeedbackController) SubmitFeedback(ctx *gin.Context) {
 	var req FeedbackRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Content is required"})
+		ctx.Error(services.Invalid("Content is required"))
 		return
 	}
 
-	userID, exists := ctx.Get("userID")
-	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.Error(services.Unauthorized("Invalid user context"))
 		return
 	}
-	userIDValue, ok := userID.(uint)
-	if !ok {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+
+	input := services.FeedbackInput{
+		Content:  req.Content,
+		Category: req.Category,
+		Metadata: req.Metadata,
+	}
+	if err := c.service.SubmitFeedback(userID, input); err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	if err := c.service.SubmitFeedback(userIDValue, req.Content); err != nil {
-		if err.Error() == "duplicate feedback submission prevented" {
-			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit feedback"})
+	ctx.JSON(http.StatusCreated, gin.H{"message": i18n.T(middleware.RequestLocale(ctx), "Feedback received")})
+}
+
+// GuestFeedbackRequest is feedback from a visitor without an account,
+// together with a solved challenge from GET /guest/challenge.
+type GuestFeedbackRequest struct {
+	Content   string            `json:"content" binding:"required"`
+	Category  string            `json:"category"`
+	Metadata  map[string]string `json:"metadata"`
+	Email     string            `json:"email" binding:"omitempty,email"`
+	Challenge string            `json:"challenge" binding:"required"`
+	Solution  string            `json:"solution" binding:"required"`
+}
+
+func (c *FeedbackController) Challenge(ctx *gin.Context) {
+	challenge, err := c.service.Challenge()
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, gin.H{"message": "Feedback received"})
+	ctx.JSON(http.StatusOK, challenge)
+}
+
+func (c *FeedbackController) SubmitGuestFeedback(ctx *gin.Context) {
+	var req GuestFeedbackRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(services.Invalid("Content, challenge and solution are required, and email must be valid"))
+		return
+	}
+
+	input := services.GuestFeedbackInput{
+		FeedbackInput: services.FeedbackInput{
+			Content:  req.Content,
+			Category: req.Category,
+			Metadata: req.Metadata,
+		},
+		Email:     req.Email,
+		Challenge: req.Challenge,
+		Solution:  req.Solution,
+	}
+	if err := c.service.SubmitGuest(input); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"message": i18n.T(middleware.RequestLocale(ctx), "Feedback received")})
+}
+
+// currentUserID returns the user ID stored in the context by AuthMiddleware.
+func currentUserID(ctx *gin.Context) (uint, bool) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		return 0, false
+	}
+	userIDValue, ok := userID.(uint)
+	return userIDValue, ok
 }
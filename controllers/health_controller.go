@@ -0,0 +1,29 @@
+package controllers
+
+import (
+	"feedback-app/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+	service *services.HealthService
+}
+
+func NewHealthController(service *services.HealthService) *HealthController {
+	return &HealthController{service: service}
+}
+
+func (c *HealthController) Live(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func (c *HealthController) Ready(ctx *gin.Context) {
+	status := c.service.Readiness(ctx.Request.Context())
+	if !status.Ready {
+		ctx.JSON(http.StatusServiceUnavailable, status)
+		return
+	}
+	ctx.JSON(http.StatusOK, status)
+}
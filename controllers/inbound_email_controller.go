@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"feedback-app/i18n"
+	"feedback-app/middleware"
+	"feedback-app/services"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxInboundEmailBytes limits the size of posted messages. Attachments are
+// not kept, so anything larger is almost certainly not a reply.
+const maxInboundEmailBytes = 10 << 20
+
+// InboundEmailController receives raw replies from the inbound mail service.
+// It is only routed when INBOUND_EMAIL_DOMAIN is set.
+type InboundEmailController struct {
+	service *services.InboundEmailService
+	token   string
+}
+
+func NewInboundEmailController(service *services.InboundEmailService, token string) *InboundEmailController {
+	return &InboundEmailController{service: service, token: token}
+}
+
+// Authorize admits only requests carrying the inbound email token.
+func (c *InboundEmailController) Authorize(ctx *gin.Context) {
+	token := strings.TrimPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(c.token)) != 1 {
+		ctx.Error(services.Unauthorized("Invalid inbound email token"))
+		ctx.Abort()
+		return
+	}
+	ctx.Next()
+}
+
+func (c *InboundEmailController) Receive(ctx *gin.Context) {
+	body := http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxInboundEmailBytes)
+	comment, err := c.service.Receive(body)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if comment == nil {
+		ctx.JSON(http.StatusAccepted, gin.H{"message": i18n.T(middleware.RequestLocale(ctx), "Automatic reply ignored")})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, comment)
+}
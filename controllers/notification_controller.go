@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"errors"
+	"feedback-app/i18n"
+	"feedback-app/middleware"
+	"feedback-app/services"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationController struct {
+	service *services.NotificationService
+}
+
+func NewNotificationController(service *services.NotificationService) *NotificationController {
+	return &NotificationController{service: service}
+}
+
+// UnsubscribePage asks for confirmation instead of unsubscribing right away,
+// because mail scanners follow links in emails.
+func (c *NotificationController) UnsubscribePage(ctx *gin.Context) {
+	ctx.HTML(http.StatusOK, "unsubscribe.html", gin.H{
+		"Title":  i18n.T(middleware.RequestLocale(ctx), "Unsubscribe"),
+		"Action": ctx.Request.URL.RequestURI(),
+		"Kind":   ctx.Query("kind"),
+		"Post":   strings.HasPrefix(ctx.Query("kind"), "post-"),
+	})
+}
+
+// Unsubscribe handles both the confirmation form and RFC 8058 one-click
+// requests sent by mail clients.
+func (c *NotificationController) Unsubscribe(ctx *gin.Context) {
+	userID, err := strconv.ParseUint(ctx.Query("user"), 10, 64)
+	if err != nil {
+		c.renderResult(ctx, http.StatusBadRequest, "This unsubscribe link is invalid.")
+		return
+	}
+
+	err = c.service.Unsubscribe(uint(userID), ctx.Query("kind"), ctx.Query("sig"))
+	switch {
+	case err == nil:
+		c.renderResult(ctx, http.StatusOK, "You have been unsubscribed.")
+	case errors.Is(err, services.ErrInvalidToken), errors.Is(err, services.ErrInvalidRequest):
+		c.renderResult(ctx, http.StatusBadRequest, "This unsubscribe link is invalid.")
+	case errors.Is(err, services.ErrNotFound):
+		c.renderResult(ctx, http.StatusNotFound, "This unsubscribe link is no longer valid.")
+	default:
+		log.Printf("Unsubscribe failed: %v", err)
+		c.renderResult(ctx, http.StatusInternalServerError, "Something went wrong, please try again later.")
+	}
+}
+
+// renderResult shows message, an i18n catalog key, in the request locale.
+func (c *NotificationController) renderResult(ctx *gin.Context, status int, message string) {
+	locale := middleware.RequestLocale(ctx)
+	ctx.HTML(status, "unsubscribe.html", gin.H{
+		"Title":   i18n.T(locale, "Unsubscribe"),
+		"Message": i18n.T(locale, message),
+	})
+}
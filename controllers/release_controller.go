@@ -0,0 +1,31 @@
+package controllers
+
+import (
+	"feedback-app/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReleaseController serves the public changelog.
+type ReleaseController struct {
+	service *services.ReleaseService
+}
+
+func NewReleaseController(service *services.ReleaseService) *ReleaseController {
+	return &ReleaseController{service: service}
+}
+
+func (c *ReleaseController) Changelog(ctx *gin.Context) {
+	limit, _ := strconv.Atoi(ctx.Query("limit"))
+	offset, _ := strconv.Atoi(ctx.Query("offset"))
+
+	releases, total, err := c.service.Changelog(limit, offset)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"releases": releases, "total": total})
+}
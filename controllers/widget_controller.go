@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"feedback-app/i18n"
+	"feedback-app/middleware"
+	"feedback-app/models"
+	"feedback-app/services"
+	"feedback-app/templates"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const widgetSiteKey = "widgetSite"
+
+// WidgetController serves the embeddable feedback widget. The script on the
+// embedding page opens a form in an iframe served from here; the form, or
+// the page itself through CORS, posts guest feedback for the site.
+type WidgetController struct {
+	sites     *services.SiteService
+	feedback  *services.FeedbackService
+	appOrigin string
+}
+
+func NewWidgetController(sites *services.SiteService, feedback *services.FeedbackService, appURL string) *WidgetController {
+	appOrigin := ""
+	if u, err := url.Parse(appURL); err == nil && u.Host != "" {
+		appOrigin = strings.ToLower(u.Scheme + "://" + u.Host)
+	}
+	return &WidgetController{sites: sites, feedback: feedback, appOrigin: appOrigin}
+}
+
+func (c *WidgetController) Script(ctx *gin.Context) {
+	c.serveScript(ctx, "widget/widget.js")
+}
+
+func (c *WidgetController) FrameScript(ctx *gin.Context) {
+	c.serveScript(ctx, "widget/frame.js")
+}
+
+func (c *WidgetController) serveScript(ctx *gin.Context, name string) {
+	script, err := templates.FS.ReadFile(name)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Header("Cache-Control", "public, max-age=3600")
+	ctx.Data(http.StatusOK, "text/javascript; charset=utf-8", script)
+}
+
+// Site loads the site named by the :key parameter and answers cross-origin
+// requests only from its allowed origins. Requests without an Origin header,
+// such as loading the frame, and requests from the frame itself pass.
+func (c *WidgetController) Site() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		site, err := c.sites.ForKey(ctx.Param("key"))
+		if err != nil {
+			ctx.Error(err)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Header("Vary", "Origin")
+		origin := ctx.GetHeader("Origin")
+		if origin != "" && !strings.EqualFold(origin, c.appOrigin) {
+			if !site.AllowsOrigin(origin) {
+				ctx.Error(services.Forbidden("Origin not allowed for this site"))
+				ctx.Abort()
+				return
+			}
+			ctx.Header("Access-Control-Allow-Origin", origin)
+		}
+
+		ctx.Set(widgetSiteKey, site)
+		ctx.Next()
+	}
+}
+
+// Preflight answers CORS preflight requests for the widget API.
+func (c *WidgetController) Preflight(ctx *gin.Context) {
+	ctx.Header("Access-Control-Allow-Methods", "GET, POST")
+	ctx.Header("Access-Control-Allow-Headers", "Content-Type, Accept-Language")
+	ctx.Header("Access-Control-Max-Age", "600")
+	ctx.Status(http.StatusNoContent)
+}
+
+// Frame renders the feedback form shown in the iframe. Only the site's
+// allowed origins may embed it.
+func (c *WidgetController) Frame(ctx *gin.Context) {
+	site := ctx.MustGet(widgetSiteKey).(*models.Site)
+
+	middleware.FrameAncestors(ctx, site.Origins()...)
+	ctx.HTML(http.StatusOK, "widget.html", gin.H{
+		"Site":       site,
+		"Page":       ctx.Query("page"),
+		"Categories": models.FeedbackCategories,
+	})
+}
+
+func (c *WidgetController) Challenge(ctx *gin.Context) {
+	challenge, err := c.feedback.Challenge()
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, challenge)
+}
+
+func (c *WidgetController) Submit(ctx *gin.Context) {
+	var req GuestFeedbackRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(services.Invalid("Content, challenge and solution are required, and email must be valid"))
+		return
+	}
+
+	site := ctx.MustGet(widgetSiteKey).(*models.Site)
+	input := services.GuestFeedbackInput{
+		FeedbackInput: services.FeedbackInput{
+			Content:  req.Content,
+			Category: req.Category,
+			Metadata: req.Metadata,
+		},
+		Email:     req.Email,
+		Challenge: req.Challenge,
+		Solution:  req.Solution,
+		SiteID:    &site.ID,
+	}
+	if err := c.feedback.SubmitGuest(input); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"message": i18n.T(middleware.RequestLocale(ctx), "Feedback received")})
+}
@@ -1,17 +1,92 @@
 package db
 
 import (
+	"feedback-app/config"
+	"feedback-app/repository"
+	"fmt"
 	"log"
+	"time"
 
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
 )
 
-func InitDB(dsn string) (*gorm.DB, error) {
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+// InitDB connects to the primary database, retrying with backoff until
+// DB_CONNECT_TIMEOUT_SECONDS elapses so the server can start before the
+// database in docker-compose. It applies the pool settings and registers the
+// optional read replica.
+func InitDB(cfg *config.Config) (*gorm.DB, error) {
+	dialector, err := dialectorFor(cfg.DatabaseDriver, cfg.DatabaseDSN)
+	if err != nil {
+		return nil, err
+	}
+
+	var db *gorm.DB
+	err = retryWithBackoff(connectTimeout(cfg), "database", func() error {
+		var openErr error
+		db, openErr = gorm.Open(dialector, &gorm.Config{})
+		return openErr
+	})
 	if err != nil {
 		log.Printf("Failed to connect to database: %v", err)
 		return nil, err
 	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(cfg.DBPool.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.DBPool.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(cfg.DBPool.ConnMaxLifetimeMinutes) * time.Minute)
+	sqlDB.SetConnMaxIdleTime(time.Duration(cfg.DBPool.ConnMaxIdleTimeMinutes) * time.Minute)
+
+	if cfg.DatabaseReplicaDSN != "" {
+		if err := registerReplica(db, cfg); err != nil {
+			log.Printf("Failed to register read replica: %v", err)
+			return nil, err
+		}
+		log.Println("Read replica registered")
+	}
+
 	return db, nil
 }
+
+func registerReplica(db *gorm.DB, cfg *config.Config) error {
+	replica, err := dialectorFor(cfg.DatabaseDriver, cfg.DatabaseReplicaDSN)
+	if err != nil {
+		return err
+	}
+
+	// The resolver opens the replica once while initializing and cannot be
+	// retried, so wait for it to accept connections first.
+	if err := WaitForDatabase(cfg.DatabaseDriver, cfg.DatabaseReplicaDSN, connectTimeout(cfg)); err != nil {
+		return err
+	}
+
+	resolver := dbresolver.Register(dbresolver.Config{
+		Replicas: []gorm.Dialector{replica},
+	}, repository.ReplicaResolver).
+		SetMaxOpenConns(cfg.DBPool.MaxOpenConns).
+		SetMaxIdleConns(cfg.DBPool.MaxIdleConns).
+		SetConnMaxLifetime(time.Duration(cfg.DBPool.ConnMaxLifetimeMinutes) * time.Minute).
+		SetConnMaxIdleTime(time.Duration(cfg.DBPool.ConnMaxIdleTimeMinutes) * time.Minute)
+	return db.Use(resolver)
+}
+
+func connectTimeout(cfg *config.Config) time.Duration {
+	return time.Duration(cfg.DBPool.ConnectTimeoutSeconds) * time.Second
+}
+
+func dialectorFor(driver string, dsn string) (gorm.Dialector, error) {
+	switch driver {
+	case config.DriverMySQL:
+		return mysql.Open(dsn), nil
+	case config.DriverPostgres:
+		return postgres.Open(dsn), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", driver)
+	}
+}
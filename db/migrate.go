@@ -1,43 +1,75 @@
 package db
 
 import (
-	"database/sql"
+	"errors"
+	"feedback-app/config"
+	"feedback-app/migrations"
+	"io/fs"
 	"log"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
 	"github.com/golang-migrate/migrate/v4/database/mysql"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	pgx "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	_ "github.com/jackc/pgx/v5/stdlib"
 )
 
-func newMigrateInstance(dsn string) (*migrate.Migrate, error) {
-	db, err := sql.Open("mysql", dsn)
+// MigrationInfo describes one embedded migration and whether it is applied.
+type MigrationInfo struct {
+	Version    uint
+	Name       string
+	Applied    bool
+	DirtyState bool
+}
+
+func newSource(driver string) (source.Driver, error) {
+	return iofs.New(migrations.FS, driver)
+}
+
+func newMigrateInstance(driver string, dsn string) (*migrate.Migrate, error) {
+	db, err := openSQL(driver, dsn)
 	if err != nil {
 		return nil, err
 	}
 
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, err
 	}
 
-	driver, err := mysql.WithInstance(db, &mysql.Config{})
+	var instance database.Driver
+	if driver == config.DriverPostgres {
+		instance, err = pgx.WithInstance(db, &pgx.Config{})
+	} else {
+		instance, err = mysql.WithInstance(db, &mysql.Config{})
+	}
 	if err != nil {
+		db.Close()
 		return nil, err
 	}
 
-	m, err := migrate.NewWithDatabaseInstance(
-		"file://migrations",
-		"mysql",
-		driver,
-	)
+	// From here on the driver owns db and closes it.
+	src, err := newSource(driver)
 	if err != nil {
+		instance.Close()
+		return nil, err
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, driver, instance)
+	if err != nil {
+		src.Close()
+		instance.Close()
 		return nil, err
 	}
 	return m, nil
 }
 
-func RunMigrations(dsn string) error {
-	m, err := newMigrateInstance(dsn)
+// withMigrate opens a migrate instance, runs fn and closes the instance.
+func withMigrate(driver string, dsn string, fn func(m *migrate.Migrate) error) error {
+	m, err := newMigrateInstance(driver, dsn)
 	if err != nil {
 		return err
 	}
@@ -45,44 +77,112 @@ func RunMigrations(dsn string) error {
 		_, _ = m.Close()
 	}()
 
-	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		return err
-	}
+	return fn(m)
+}
+
+func RunMigrations(driver string, dsn string) error {
+	return withMigrate(driver, dsn, func(m *migrate.Migrate) error {
+		if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+			return err
+		}
 
-	log.Println("Migrations ran successfully")
-	return nil
+		log.Println("Migrations ran successfully")
+		return nil
+	})
 }
 
-func RevertMigrations(dsn string) error {
-	m, err := newMigrateInstance(dsn)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		_, _ = m.Close()
-	}()
+func RevertMigrations(driver string, dsn string) error {
+	return withMigrate(driver, dsn, func(m *migrate.Migrate) error {
+		if err := m.Down(); err != nil && err != migrate.ErrNoChange {
+			return err
+		}
 
-	if err := m.Down(); err != nil && err != migrate.ErrNoChange {
-		return err
-	}
+		log.Println("Migrations reverted successfully")
+		return nil
+	})
+}
 
-	log.Println("Migrations reverted successfully")
-	return nil
+// MigrateSteps applies n pending migrations, or reverts -n when n is negative.
+func MigrateSteps(driver string, dsn string, n int) error {
+	return withMigrate(driver, dsn, func(m *migrate.Migrate) error {
+		if err := m.Steps(n); err != nil && err != migrate.ErrNoChange {
+			return err
+		}
+
+		log.Printf("Migrated %d step(s)", n)
+		return nil
+	})
 }
 
-func ForceVersion(dsn string, version int) error {
-	m, err := newMigrateInstance(dsn)
-	if err != nil {
+// MigrateTo moves the schema up or down to exactly version.
+func MigrateTo(driver string, dsn string, version uint) error {
+	return withMigrate(driver, dsn, func(m *migrate.Migrate) error {
+		if err := m.Migrate(version); err != nil && err != migrate.ErrNoChange {
+			return err
+		}
+
+		log.Printf("Migrated to version %d", version)
+		return nil
+	})
+}
+
+func ForceVersion(driver string, dsn string, version int) error {
+	return withMigrate(driver, dsn, func(m *migrate.Migrate) error {
+		if err := m.Force(version); err != nil {
+			return err
+		}
+
+		log.Printf("Forced migration version to %d", version)
+		return nil
+	})
+}
+
+// MigrationVersion returns the current schema version. A database without
+// any applied migration reports version 0.
+func MigrationVersion(driver string, dsn string) (version uint, dirty bool, err error) {
+	err = withMigrate(driver, dsn, func(m *migrate.Migrate) error {
+		version, dirty, err = m.Version()
+		if errors.Is(err, migrate.ErrNilVersion) {
+			version, dirty, err = 0, false, nil
+		}
 		return err
+	})
+	return version, dirty, err
+}
+
+// MigrationStatus lists every embedded migration for driver together with
+// whether it has been applied to the database.
+func MigrationStatus(driver string, dsn string) ([]MigrationInfo, error) {
+	current, dirty, err := MigrationVersion(driver, dsn)
+	if err != nil {
+		return nil, err
 	}
-	defer func() {
-		_, _ = m.Close()
-	}()
 
-	if err := m.Force(version); err != nil {
-		return err
+	src, err := newSource(driver)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	var infos []MigrationInfo
+	version, err := src.First()
+	for err == nil {
+		name := ""
+		if r, identifier, readErr := src.ReadUp(version); readErr == nil {
+			name = identifier
+			_ = r.Close()
+		}
+		infos = append(infos, MigrationInfo{
+			Version:    version,
+			Name:       name,
+			Applied:    version <= current,
+			DirtyState: version == current && dirty,
+		})
+		version, err = src.Next(version)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
 	}
 
-	log.Printf("Forced migration version to %d", version)
-	return nil
+	return infos, nil
 }
@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"feedback-app/config"
+	"fmt"
+	"io/fs"
+	"log"
+	"time"
+)
+
+// migrationLockName identifies the advisory lock taken while migrating on
+// server start. It is separate from golang-migrate's own lock, which only
+// guards individual runs and does not stop replicas from racing the dirty
+// version check.
+const (
+	migrationLockName = "feedback_app_migrate"
+	migrationLockID   = 7_320_611_482
+)
+
+// ErrDirtySchema is returned when a previous migration failed half way.
+var ErrDirtySchema = errors.New("database schema is dirty; fix it and run cmd/migrate force")
+
+// RunMigrationsLocked applies pending migrations while holding a database
+// wide advisory lock (MySQL GET_LOCK, PostgreSQL pg_advisory_lock), so only
+// one replica migrates at a time and the others wait and then find nothing
+// to do. It refuses to migrate a dirty schema.
+func RunMigrationsLocked(driver string, dsn string, timeout time.Duration) error {
+	sqlDB, err := openSQL(driver, dsn)
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	log.Println("Waiting for migration lock...")
+	if err := acquireMigrationLock(ctx, conn, driver, timeout); err != nil {
+		return err
+	}
+	defer func() {
+		if err := releaseMigrationLock(conn, driver); err != nil {
+			log.Printf("Failed to release migration lock: %v", err)
+		}
+	}()
+
+	_, dirty, err := MigrationVersion(driver, dsn)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return ErrDirtySchema
+	}
+
+	return RunMigrations(driver, dsn)
+}
+
+func openSQL(driver string, dsn string) (*sql.DB, error) {
+	switch driver {
+	case config.DriverMySQL:
+		return sql.Open("mysql", dsn)
+	case config.DriverPostgres:
+		return sql.Open("pgx", dsn)
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", driver)
+	}
+}
+
+func acquireMigrationLock(ctx context.Context, conn *sql.Conn, driver string, timeout time.Duration) error {
+	if driver == config.DriverPostgres {
+		// pg_advisory_lock blocks until acquired; ctx bounds the wait.
+		_, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID)
+		return err
+	}
+
+	var acquired sql.NullInt64
+	err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", migrationLockName, int(timeout.Seconds())).Scan(&acquired)
+	if err != nil {
+		return err
+	}
+	if !acquired.Valid || acquired.Int64 != 1 {
+		return fmt.Errorf("timed out after %s waiting for migration lock", timeout)
+	}
+	return nil
+}
+
+func releaseMigrationLock(conn *sql.Conn, driver string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if driver == config.DriverPostgres {
+		_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", migrationLockID)
+		return err
+	}
+	_, err := conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", migrationLockName)
+	return err
+}
+
+// LatestMigrationVersion returns the highest embedded migration version for
+// driver, i.e. the schema version this build expects.
+func LatestMigrationVersion(driver string) (uint, error) {
+	src, err := newSource(driver)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	latest, err := src.First()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		next, err := src.Next(latest)
+		if errors.Is(err, fs.ErrNotExist) {
+			return latest, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		latest = next
+	}
+}
@@ -0,0 +1,49 @@
+package db
+
+import (
+	"log"
+	"time"
+)
+
+const (
+	initialRetryDelay = 500 * time.Millisecond
+	maxRetryDelay     = 10 * time.Second
+)
+
+// retryWithBackoff calls fn until it succeeds or timeout elapses, doubling
+// the delay between attempts up to maxRetryDelay. The last error is returned
+// on timeout.
+func retryWithBackoff(timeout time.Duration, what string, fn func() error) error {
+	deadline := time.Now().Add(timeout)
+	delay := initialRetryDelay
+
+	for {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if time.Now().Add(delay).After(deadline) {
+			return err
+		}
+
+		log.Printf("Waiting for %s: %v (retrying in %s)", what, err, delay)
+		time.Sleep(delay)
+
+		delay *= 2
+		if delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
+
+// WaitForDatabase blocks until the database accepts connections or timeout
+// elapses.
+func WaitForDatabase(driver string, dsn string, timeout time.Duration) error {
+	sqlDB, err := openSQL(driver, dsn)
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	return retryWithBackoff(timeout, "database", sqlDB.Ping)
+}
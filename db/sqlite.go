@@ -0,0 +1,47 @@
+package db
+
+import (
+	"feedback-app/models"
+	"log"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// InitSQLite opens a pure-Go SQLite database and creates the schema from the
+// models, since the SQL migrations target MySQL and PostgreSQL only. It is
+// meant for tests and local experiments; pass "file::memory:?cache=shared"
+// for an in-memory database that lives as long as the process.
+func InitSQLite(dsn string) (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		log.Printf("Failed to open sqlite database: %v", err)
+		return nil, err
+	}
+
+	if err := AutoMigrate(db); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// AutoMigrate creates or updates the tables for every model.
+func AutoMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&models.User{},
+		&models.MagicLink{},
+		&models.Site{},
+		&models.ChallengeRedemption{},
+		&models.Feedback{},
+		&models.Tag{},
+		&models.OutboundEmail{},
+		&models.Comment{},
+		&models.CommentEdit{},
+		&models.Post{},
+		&models.PostVote{},
+		&models.PostVoteEvent{},
+		&models.PostSubscription{},
+		&models.PostComment{},
+		&models.Release{},
+	)
+}
@@ -0,0 +1,96 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// errorHelpers are the services constructors whose message is shown to
+// users through middleware.ErrorHandler.
+var errorHelpers = map[string]bool{
+	"Invalid":      true,
+	"Invalidf":     true,
+	"Unauthorized": true,
+	"Forbidden":    true,
+	"NotFound":     true,
+	"Conflict":     true,
+}
+
+// TestServiceErrorsTranslated fails when a message passed to one of the
+// services error helpers, or set on a services.Error literal, has no entry in
+// every catalog.
+func TestServiceErrorsTranslated(t *testing.T) {
+	files, err := filepath.Glob("../services/*.go")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("list services sources: %v", err)
+	}
+
+	messages := map[string]token.Position{}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		parsed, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatalf("parse %s: %v", file, err)
+		}
+		ast.Inspect(parsed, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.CallExpr:
+				if ident, ok := n.Fun.(*ast.Ident); ok && errorHelpers[ident.Name] && len(n.Args) > 0 {
+					addMessage(messages, fset, n.Args[0])
+				}
+			case *ast.CompositeLit:
+				if ident, ok := n.Type.(*ast.Ident); ok && ident.Name == "Error" {
+					for _, elt := range n.Elts {
+						if kv, ok := elt.(*ast.KeyValueExpr); ok {
+							if key, ok := kv.Key.(*ast.Ident); ok && key.Name == "Message" {
+								addMessage(messages, fset, kv.Value)
+							}
+						}
+					}
+				}
+			}
+			return true
+		})
+	}
+	if len(messages) == 0 {
+		t.Fatal("found no error messages in services")
+	}
+
+	keys := make([]string, 0, len(messages))
+	for message := range messages {
+		keys = append(keys, message)
+	}
+	sort.Strings(keys)
+	for _, locale := range Supported[1:] {
+		for _, message := range keys {
+			if _, ok := catalogs[locale][message]; !ok {
+				t.Errorf("%s: %q has no %s translation", messages[message], message, locale)
+			}
+		}
+	}
+}
+
+// addMessage records expr if it is a string literal. Messages built at run
+// time cannot be checked and are expected to be formatted with Invalidf.
+func addMessage(messages map[string]token.Position, fset *token.FileSet, expr ast.Expr) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return
+	}
+	message, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return
+	}
+	if _, seen := messages[message]; !seen {
+		messages[message] = fset.Position(lit.Pos())
+	}
+}
@@ -0,0 +1,108 @@
+// Package i18n picks a locale for each user or request and translates
+// user-facing messages. Messages are looked up by their English text, so
+// English needs no catalog and an untranslated message falls back to it.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Default is used when nothing better matches.
+const Default = "en"
+
+// Supported lists every locale with translations, Default first.
+var Supported = []string{Default, "de", "es"}
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+var (
+	catalogs = mustLoadCatalogs()
+	matcher  = newMatcher()
+)
+
+func mustLoadCatalogs() map[string]map[string]string {
+	catalogs := make(map[string]map[string]string, len(Supported))
+	for _, locale := range Supported[1:] {
+		content, err := localeFS.ReadFile(path.Join("locales", locale+".json"))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: missing catalog for %s: %v", locale, err))
+		}
+		catalog := map[string]string{}
+		if err := json.Unmarshal(content, &catalog); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog for %s: %v", locale, err))
+		}
+		catalogs[locale] = catalog
+	}
+	return catalogs
+}
+
+func newMatcher() language.Matcher {
+	tags := make([]language.Tag, 0, len(Supported))
+	for _, locale := range Supported {
+		tags = append(tags, language.MustParse(locale))
+	}
+	return language.NewMatcher(tags)
+}
+
+// IsSupported reports whether locale is one of Supported.
+func IsSupported(locale string) bool {
+	for _, l := range Supported {
+		if l == locale {
+			return true
+		}
+	}
+	return false
+}
+
+// Negotiate picks the best supported locale for an Accept-Language header,
+// e.g. "de-AT,de;q=0.9,en;q=0.5" yields "de".
+func Negotiate(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return Default
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return Default
+	}
+	return Supported[index]
+}
+
+// Preferred returns the first supported locale among candidates, typically a
+// user's stored preference followed by the negotiated request locale.
+func Preferred(candidates ...string) string {
+	for _, locale := range candidates {
+		if IsSupported(locale) {
+			return locale
+		}
+	}
+	return Default
+}
+
+// T translates message into locale and formats it with args. Placeholders
+// must appear in the same order in every translation.
+func T(locale string, message string, args ...interface{}) string {
+	if translated, ok := catalogs[locale][message]; ok && strings.TrimSpace(translated) != "" {
+		message = translated
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// Fallbacks returns locale followed by Default, for looking up localized
+// resources such as email templates.
+func Fallbacks(locale string) []string {
+	if locale == Default || !IsSupported(locale) {
+		return []string{Default}
+	}
+	return []string{locale, Default}
+}
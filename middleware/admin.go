@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"feedback-app/repository"
+	"feedback-app/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminSessionCookie holds the JWT of a signed-in dashboard user.
+const AdminSessionCookie = "admin_session"
+
+// AdminMiddleware guards the server-rendered dashboard. It reads the session
+// JWT from a cookie rather than the Authorization header, and requires the
+// user to still hold the admin role on every request.
+func AdminMiddleware(secret string, users repository.UserStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, err := c.Cookie(AdminSessionCookie)
+		if err != nil || token == "" {
+			c.Redirect(http.StatusFound, "/admin/login")
+			c.Abort()
+			return
+		}
+
+		claims, err := utils.ParseJWT(token, secret)
+		if err != nil {
+			c.Redirect(http.StatusFound, "/admin/login")
+			c.Abort()
+			return
+		}
+
+		user, err := users.FindByID(claims.UserID)
+		if err != nil || !user.IsAdmin() {
+			c.String(http.StatusForbidden, "Forbidden")
+			c.Abort()
+			return
+		}
+
+		c.Set("userID", user.ID)
+		c.Set("adminUser", user)
+		c.Next()
+	}
+}
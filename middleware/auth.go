@@ -1,8 +1,8 @@
 package middleware
 
 import (
+	"feedback-app/services"
 	"feedback-app/utils"
-	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -12,21 +12,21 @@ func AuthMiddleware(secret string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+			c.Error(services.Unauthorized("Authorization header required"))
 			c.Abort()
 			return
 		}
 
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization format"})
+			c.Error(services.Unauthorized("Invalid authorization format"))
 			c.Abort()
 			return
 		}
 
 		claims, err := utils.ParseJWT(parts[1], secret)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			c.Error(services.Unauthorized("Invalid or expired token"))
 			c.Abort()
 			return
 		}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"feedback-app/services"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORSConfig is the cross-origin policy of a route group. An origin of "*"
+// allows every origin and cannot be combined with Credentials.
+type CORSConfig struct {
+	Origins     []string
+	Methods     []string
+	Headers     []string
+	Credentials bool
+	MaxAge      time.Duration
+}
+
+// CORS answers cross-origin requests from the configured origins. Preflight
+// requests are answered here and never reach the route, so register the
+// group's OPTIONS routes with Preflight. Preflights from other origins are
+// refused; other requests pass either way, since the browser enforces the
+// policy on the response and same-origin form posts send an Origin too.
+func CORS(cfg CORSConfig) gin.HandlerFunc {
+	anyOrigin := false
+	allowed := make(map[string]bool, len(cfg.Origins))
+	for _, origin := range cfg.Origins {
+		if origin == "*" {
+			anyOrigin = true
+		}
+		allowed[strings.ToLower(origin)] = true
+	}
+	methods := strings.Join(cfg.Methods, ", ")
+	headers := strings.Join(cfg.Headers, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		h := c.Writer.Header()
+		h.Add("Vary", "Origin")
+		if !anyOrigin && !allowed[strings.ToLower(origin)] {
+			if preflight {
+				c.Error(services.Forbidden("Origin not allowed"))
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if anyOrigin {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if cfg.Credentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if preflight {
+			h.Set("Access-Control-Allow-Methods", methods)
+			if headers != "" {
+				h.Set("Access-Control-Allow-Headers", headers)
+			}
+			h.Set("Access-Control-Max-Age", maxAge)
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
+
+// Preflight handles OPTIONS routes of a group guarded by CORS. Real
+// preflights are answered by CORS; this only sees plain OPTIONS requests.
+func Preflight(c *gin.Context) {
+	c.Status(http.StatusNoContent)
+}
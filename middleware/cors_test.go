@@ -0,0 +1,134 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"feedback-app/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newCORSRouter(cfg middleware.CORSConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	group := r.Group("/api", middleware.CORS(cfg))
+	group.OPTIONS("/items", middleware.Preflight)
+	group.GET("/items", func(c *gin.Context) { c.String(http.StatusOK, "items") })
+	group.POST("/items", func(c *gin.Context) { c.String(http.StatusCreated, "created") })
+	return r
+}
+
+func TestCORS(t *testing.T) {
+	cfg := middleware.CORSConfig{
+		Origins:     []string{"https://app.example.com"},
+		Methods:     []string{"GET", "POST"},
+		Headers:     []string{"Authorization", "Content-Type"},
+		Credentials: true,
+		MaxAge:      10 * time.Minute,
+	}
+
+	tests := []struct {
+		name      string
+		method    string
+		origin    string
+		preflight bool
+		want      int
+		headers   map[string]string
+	}{
+		{
+			name: "same origin", method: http.MethodGet, want: http.StatusOK,
+			headers: map[string]string{"Access-Control-Allow-Origin": "", "Vary": ""},
+		},
+		{
+			name: "allowed origin", method: http.MethodGet, origin: "https://app.example.com", want: http.StatusOK,
+			headers: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.example.com",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Allow-Methods":     "",
+				"Vary":                             "Origin",
+			},
+		},
+		{
+			name: "allowed origin in other case", method: http.MethodPost, origin: "HTTPS://APP.EXAMPLE.COM", want: http.StatusCreated,
+			headers: map[string]string{"Access-Control-Allow-Origin": "HTTPS://APP.EXAMPLE.COM", "Vary": "Origin"},
+		},
+		{
+			name: "disallowed origin", method: http.MethodGet, origin: "https://evil.example.com", want: http.StatusOK,
+			headers: map[string]string{
+				"Access-Control-Allow-Origin":      "",
+				"Access-Control-Allow-Credentials": "",
+				"Vary":                             "Origin",
+			},
+		},
+		{
+			name: "preflight", method: http.MethodOptions, origin: "https://app.example.com", preflight: true, want: http.StatusNoContent,
+			headers: map[string]string{
+				"Access-Control-Allow-Origin":  "https://app.example.com",
+				"Access-Control-Allow-Methods": "GET, POST",
+				"Access-Control-Allow-Headers": "Authorization, Content-Type",
+				"Access-Control-Max-Age":       "600",
+				"Vary":                         "Origin",
+			},
+		},
+		{
+			name: "preflight from disallowed origin", method: http.MethodOptions, origin: "https://evil.example.com", preflight: true, want: http.StatusForbidden,
+			headers: map[string]string{
+				"Access-Control-Allow-Origin":  "",
+				"Access-Control-Allow-Methods": "",
+				"Vary":                         "Origin",
+			},
+		},
+		{
+			name: "plain OPTIONS", method: http.MethodOptions, origin: "https://app.example.com", want: http.StatusNoContent,
+			headers: map[string]string{"Access-Control-Allow-Methods": ""},
+		},
+	}
+
+	r := newCORSRouter(cfg)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/items", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status %d, want %d", rec.Code, tt.want)
+			}
+			for name, want := range tt.headers {
+				if got := rec.Header().Get(name); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCORSAnyOrigin(t *testing.T) {
+	r := newCORSRouter(middleware.CORSConfig{Origins: []string{"*"}, Methods: []string{"GET"}})
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/items", nil)
+	req.Header.Set("Origin", "https://anywhere.example.org")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want *", got)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("Access-Control-Allow-Credentials = %q, want none", got)
+	}
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"errors"
+	"feedback-app/i18n"
+	"feedback-app/services"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details body. Code is an extension member
+// carrying the stable services.Error code.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+}
+
+var statusByCode = map[string]int{
+	services.CodeInvalidRequest:      http.StatusBadRequest,
+	services.CodeUnauthorized:        http.StatusUnauthorized,
+	services.CodeForbidden:           http.StatusForbidden,
+	services.CodeNotFound:            http.StatusNotFound,
+	services.CodeConflict:            http.StatusConflict,
+	services.CodeInvalidToken:        http.StatusUnauthorized,
+	services.CodeTokenUsed:           http.StatusUnauthorized,
+	services.CodeTokenExpired:        http.StatusUnauthorized,
+	services.CodeDuplicateFeedback:   http.StatusConflict,
+	services.CodeInvalidChallenge:    http.StatusForbidden,
+	services.CodeInvalidStatus:       http.StatusBadRequest,
+	services.CodeRateLimited:         http.StatusTooManyRequests,
+	services.CodeEmailDelivery:       http.StatusBadGateway,
+	services.CodeRedirectUnavailable: http.StatusInternalServerError,
+}
+
+// ErrorHandler renders the last error attached with ctx.Error as
+// problem+json. Errors that are not a *services.Error are logged and reported
+// as a generic internal error so internal details never reach clients.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		WritePendingError(c)
+	}
+}
+
+// WritePendingError renders the last error attached with ctx.Error unless a
+// response was already written. Middleware that inspects the response calls
+// it after c.Next so that it sees the problem body.
+func WritePendingError(c *gin.Context) {
+	if len(c.Errors) == 0 || c.Writer.Written() {
+		return
+	}
+
+	WriteProblem(c, c.Errors.Last().Err)
+}
+
+// WriteProblem writes err as a problem+json response in the request locale
+// and aborts the chain.
+func WriteProblem(c *gin.Context, err error) {
+	locale := RequestLocale(c)
+	problem := NewProblem(err, c.Request.URL.Path, locale)
+	c.Header("Content-Type", problemContentType)
+	c.Header("Content-Language", locale)
+	c.AbortWithStatusJSON(problem.Status, problem)
+}
+
+// NewProblem maps err onto a Problem for the request path instance, with the
+// title and detail translated into locale. Code is never translated.
+func NewProblem(err error, instance string, locale string) Problem {
+	var appErr *services.Error
+	if !errors.As(err, &appErr) {
+		log.Printf("Unhandled error on %s: %v", instance, err)
+		return Problem{
+			Type:     "about:blank",
+			Title:    i18n.T(locale, http.StatusText(http.StatusInternalServerError)),
+			Status:   http.StatusInternalServerError,
+			Detail:   i18n.T(locale, "An unexpected error occurred"),
+			Instance: instance,
+			Code:     services.CodeInternal,
+		}
+	}
+
+	status, ok := statusByCode[appErr.Code]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	return Problem{
+		Type:     "about:blank",
+		Title:    i18n.T(locale, http.StatusText(status)),
+		Status:   status,
+		Detail:   i18n.T(locale, appErr.Message, appErr.Args...),
+		Instance: instance,
+		Code:     appErr.Code,
+	}
+}
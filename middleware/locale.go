@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"feedback-app/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+const localeKey = "locale"
+
+// RequestLocale negotiates the response locale from the Accept-Language
+// header once per request and caches it in the context.
+func RequestLocale(c *gin.Context) string {
+	if locale := c.GetString(localeKey); locale != "" {
+		return locale
+	}
+	locale := i18n.Negotiate(c.GetHeader("Accept-Language"))
+	c.Set(localeKey, locale)
+	return locale
+}
@@ -1,7 +1,8 @@
 package middleware
 
 import (
-	"net/http"
+	"feedback-app/services"
+	"strconv"
 	"sync"
 	"time"
 
@@ -32,28 +33,43 @@ func NewRateLimiter(limit time.Duration) *RateLimiter {
 	}
 }
 
+// Limit limits each client IP on each request path.
 func (rl *RateLimiter) Limit() gin.HandlerFunc {
+	return rl.limitBy(func(c *gin.Context) (string, string) {
+		return c.ClientIP(), c.Request.URL.Path
+	})
+}
+
+// LimitUser limits each authenticated user on each route, whatever its path
+// parameters, so a user cannot spread requests over many posts. It must run
+// after AuthMiddleware.
+func (rl *RateLimiter) LimitUser() gin.HandlerFunc {
+	return rl.limitBy(func(c *gin.Context) (string, string) {
+		return "user:" + strconv.FormatUint(uint64(c.GetUint("userID")), 10), c.FullPath()
+	})
+}
+
+func (rl *RateLimiter) limitBy(key func(c *gin.Context) (client string, path string)) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		clientIP := c.ClientIP()
-		path := c.Request.URL.Path
+		client, path := key(c)
 		now := time.Now()
 
 		rl.mutex.Lock()
 		defer rl.mutex.Unlock()
 
-		if _, exists := rl.visitors[clientIP]; !exists {
-			rl.visitors[clientIP] = make(map[string]*visitor)
+		if _, exists := rl.visitors[client]; !exists {
+			rl.visitors[client] = make(map[string]*visitor)
 		}
 
-		v, exists := rl.visitors[clientIP][path]
+		v, exists := rl.visitors[client][path]
 		if !exists {
 			v = &visitor{limiter: rate.NewLimiter(rl.limit, rl.burst)}
-			rl.visitors[clientIP][path] = v
+			rl.visitors[client][path] = v
 		}
 
 		v.lastSeen = now
 		if !v.limiter.Allow() {
-			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded. Please try again later."})
+			c.Error(services.ErrRateLimited)
 			c.Abort()
 			return
 		}
@@ -68,14 +84,14 @@ func (rl *RateLimiter) cleanup(now time.Time) {
 		return
 	}
 
-	for ip, paths := range rl.visitors {
+	for client, paths := range rl.visitors {
 		for path, v := range paths {
 			if now.Sub(v.lastSeen) > rl.entryTTL {
 				delete(paths, path)
 			}
 		}
 		if len(paths) == 0 {
-			delete(rl.visitors, ip)
+			delete(rl.visitors, client)
 		}
 	}
 
@@ -0,0 +1,105 @@
+package middleware_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"feedback-app/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	testAPIPolicy  = "default-src 'none'; frame-ancestors 'none'"
+	testPagePolicy = "default-src 'self'; frame-ancestors 'none'"
+)
+
+func newSecurityRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.SecurityHeaders(middleware.SecurityHeadersConfig{
+		HSTSMaxAge:            365 * 24 * time.Hour,
+		HSTSIncludeSubdomains: true,
+		ReferrerPolicy:        "strict-origin-when-cross-origin",
+		ContentSecurityPolicy: testAPIPolicy,
+	}))
+	r.GET("/api/items", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) })
+
+	pages := r.Group("/", middleware.ContentSecurityPolicy(testPagePolicy))
+	pages.GET("/page", func(c *gin.Context) { c.String(http.StatusOK, "page") })
+	pages.GET("/widget/frame", func(c *gin.Context) {
+		middleware.FrameAncestors(c, "https://shop.example.com", "https://www.shop.example.com")
+		c.String(http.StatusOK, "widget")
+	})
+	pages.GET("/widget/unlisted", func(c *gin.Context) {
+		middleware.FrameAncestors(c)
+		c.String(http.StatusOK, "widget")
+	})
+	return r
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	tests := []struct {
+		name          string
+		path          string
+		csp           string
+		xFrameOptions string
+	}{
+		{"api", "/api/items", testAPIPolicy, "DENY"},
+		{"page", "/page", testPagePolicy, "DENY"},
+		{"widget", "/widget/frame", "default-src 'self'; frame-ancestors https://shop.example.com https://www.shop.example.com", ""},
+		{"widget without origins", "/widget/unlisted", "default-src 'self'; frame-ancestors 'none'", "DENY"},
+	}
+
+	r := newSecurityRouter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			h := rec.Header()
+			if got := h.Get("Content-Security-Policy"); got != tt.csp {
+				t.Errorf("Content-Security-Policy = %q, want %q", got, tt.csp)
+			}
+			if got := h.Get("X-Frame-Options"); got != tt.xFrameOptions {
+				t.Errorf("X-Frame-Options = %q, want %q", got, tt.xFrameOptions)
+			}
+			if got := h.Get("X-Content-Type-Options"); got != "nosniff" {
+				t.Errorf("X-Content-Type-Options = %q, want nosniff", got)
+			}
+			if got := h.Get("Referrer-Policy"); got != "strict-origin-when-cross-origin" {
+				t.Errorf("Referrer-Policy = %q", got)
+			}
+		})
+	}
+}
+
+func TestSecurityHeadersHSTS(t *testing.T) {
+	const want = "max-age=31536000; includeSubDomains"
+	tests := []struct {
+		name    string
+		prepare func(*http.Request)
+		hsts    string
+	}{
+		{"http", func(*http.Request) {}, ""},
+		{"tls", func(r *http.Request) { r.TLS = &tls.ConnectionState{} }, want},
+		{"forwarded https", func(r *http.Request) { r.Header.Set("X-Forwarded-Proto", "https") }, want},
+		{"forwarded http", func(r *http.Request) { r.Header.Set("X-Forwarded-Proto", "http") }, ""},
+	}
+
+	r := newSecurityRouter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/items", nil)
+			tt.prepare(req)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			if got := rec.Header().Get("Strict-Transport-Security"); got != tt.hsts {
+				t.Errorf("Strict-Transport-Security = %q, want %q", got, tt.hsts)
+			}
+		})
+	}
+}
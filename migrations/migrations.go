@@ -0,0 +1,9 @@
+// Package migrations embeds the SQL migrations so that binaries do not depend
+// on the working directory. Each supported database driver has its own
+// directory with identically numbered files.
+package migrations
+
+import "embed"
+
+//go:embed mysql/*.sql postgres/*.sql
+var FS embed.FS
@@ -0,0 +1,23 @@
+ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user';
+
+ALTER TABLE feedbacks
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'new',
+    ADD COLUMN updated_at DATETIME;
+
+CREATE INDEX idx_feedbacks_status ON feedbacks(status);
+
+CREATE INDEX idx_feedbacks_created_at ON feedbacks(created_at);
+
+CREATE TABLE IF NOT EXISTS tags (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    created_at DATETIME
+);
+
+CREATE TABLE IF NOT EXISTS feedback_tags (
+    feedback_id INT NOT NULL,
+    tag_id INT NOT NULL,
+    PRIMARY KEY(feedback_id, tag_id),
+    FOREIGN KEY(feedback_id) REFERENCES feedbacks(id) ON DELETE CASCADE,
+    FOREIGN KEY(tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
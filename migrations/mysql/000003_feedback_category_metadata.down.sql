@@ -0,0 +1,5 @@
+DROP INDEX idx_feedbacks_category ON feedbacks;
+
+ALTER TABLE feedbacks
+    DROP COLUMN metadata,
+    DROP COLUMN category;
\ No newline at end of file
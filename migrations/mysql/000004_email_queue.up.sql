@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS outbound_emails (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    recipient VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    text_body TEXT,
+    html_body TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at DATETIME NOT NULL,
+    sent_at DATETIME NULL,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+
+CREATE INDEX idx_outbound_emails_status_next ON outbound_emails(status, next_attempt_at);
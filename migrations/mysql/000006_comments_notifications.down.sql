@@ -0,0 +1,7 @@
+ALTER TABLE outbound_emails DROP COLUMN headers;
+
+ALTER TABLE users
+    DROP COLUMN notify_on_status,
+    DROP COLUMN notify_on_reply;
+
+DROP TABLE IF EXISTS comments;
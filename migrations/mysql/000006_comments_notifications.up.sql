@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS comments (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    feedback_id INT NOT NULL,
+    author_id INT NOT NULL,
+    body TEXT NOT NULL,
+    created_at DATETIME,
+    updated_at DATETIME,
+    FOREIGN KEY(feedback_id) REFERENCES feedbacks(id) ON DELETE CASCADE,
+    FOREIGN KEY(author_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_comments_feedback_id ON comments(feedback_id);
+
+ALTER TABLE users
+    ADD COLUMN notify_on_reply BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN notify_on_status BOOLEAN NOT NULL DEFAULT TRUE;
+
+ALTER TABLE outbound_emails ADD COLUMN headers JSON NULL;
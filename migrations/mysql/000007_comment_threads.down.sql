@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS comment_edits;
+
+ALTER TABLE comments
+    DROP COLUMN edited_at,
+    DROP COLUMN visibility;
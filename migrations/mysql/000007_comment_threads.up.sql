@@ -0,0 +1,15 @@
+ALTER TABLE comments
+    ADD COLUMN visibility VARCHAR(20) NOT NULL DEFAULT 'public',
+    ADD COLUMN edited_at DATETIME NULL;
+
+CREATE TABLE IF NOT EXISTS comment_edits (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    comment_id INT NOT NULL,
+    editor_id INT NOT NULL,
+    body TEXT NOT NULL,
+    created_at DATETIME,
+    FOREIGN KEY(comment_id) REFERENCES comments(id) ON DELETE CASCADE,
+    FOREIGN KEY(editor_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_comment_edits_comment_id ON comment_edits(comment_id);
@@ -0,0 +1,48 @@
+CREATE TABLE IF NOT EXISTS posts (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    feedback_id INT NOT NULL,
+    title VARCHAR(200) NOT NULL,
+    body TEXT NOT NULL,
+    published_at DATETIME NULL,
+    created_at DATETIME,
+    updated_at DATETIME,
+    UNIQUE KEY idx_posts_feedback_id (feedback_id),
+    FOREIGN KEY(feedback_id) REFERENCES feedbacks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_posts_published_at ON posts(published_at);
+
+CREATE TABLE IF NOT EXISTS post_votes (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    post_id INT NOT NULL,
+    user_id INT NOT NULL,
+    created_at DATETIME,
+    UNIQUE KEY idx_post_votes_post_user (post_id, user_id),
+    FOREIGN KEY(post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_post_votes_user_created ON post_votes(user_id, created_at);
+
+CREATE TABLE IF NOT EXISTS post_subscriptions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    post_id INT NOT NULL,
+    user_id INT NOT NULL,
+    created_at DATETIME,
+    UNIQUE KEY idx_post_subscriptions_post_user (post_id, user_id),
+    FOREIGN KEY(post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS post_comments (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    post_id INT NOT NULL,
+    author_id INT NOT NULL,
+    body TEXT NOT NULL,
+    created_at DATETIME,
+    updated_at DATETIME,
+    FOREIGN KEY(post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY(author_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_post_comments_post_id ON post_comments(post_id);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS releases (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    version VARCHAR(50) NOT NULL,
+    title VARCHAR(200) NOT NULL,
+    notes TEXT NOT NULL,
+    published_at DATETIME NULL,
+    created_at DATETIME,
+    updated_at DATETIME,
+    UNIQUE KEY idx_releases_version (version)
+);
+
+CREATE INDEX idx_releases_published_at ON releases(published_at);
+
+CREATE TABLE IF NOT EXISTS release_feedback (
+    release_id INT NOT NULL,
+    feedback_id INT NOT NULL,
+    PRIMARY KEY(release_id, feedback_id),
+    FOREIGN KEY(release_id) REFERENCES releases(id) ON DELETE CASCADE,
+    FOREIGN KEY(feedback_id) REFERENCES feedbacks(id) ON DELETE CASCADE
+);
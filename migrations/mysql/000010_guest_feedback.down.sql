@@ -0,0 +1,8 @@
+DELETE FROM feedbacks WHERE user_id IS NULL;
+
+DROP INDEX idx_feedbacks_moderation ON feedbacks;
+
+ALTER TABLE feedbacks
+    DROP COLUMN moderation,
+    DROP COLUMN guest_email,
+    MODIFY user_id INT NOT NULL;
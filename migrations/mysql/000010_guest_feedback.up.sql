@@ -0,0 +1,6 @@
+ALTER TABLE feedbacks
+    MODIFY user_id INT NULL,
+    ADD COLUMN guest_email VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN moderation VARCHAR(20) NOT NULL DEFAULT 'approved';
+
+CREATE INDEX idx_feedbacks_moderation ON feedbacks(moderation);
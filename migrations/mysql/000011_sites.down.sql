@@ -0,0 +1,7 @@
+ALTER TABLE feedbacks DROP FOREIGN KEY fk_feedbacks_site;
+
+DROP INDEX idx_feedbacks_site_id ON feedbacks;
+
+ALTER TABLE feedbacks DROP COLUMN site_id;
+
+DROP TABLE IF EXISTS sites;
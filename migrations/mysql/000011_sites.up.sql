@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS sites (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    public_key VARCHAR(64) NOT NULL,
+    allowed_origins TEXT NOT NULL,
+    created_at DATETIME,
+    updated_at DATETIME,
+    UNIQUE KEY idx_sites_public_key (public_key)
+);
+
+ALTER TABLE feedbacks ADD COLUMN site_id INT NULL;
+
+CREATE INDEX idx_feedbacks_site_id ON feedbacks(site_id);
+
+ALTER TABLE feedbacks
+    ADD CONSTRAINT fk_feedbacks_site FOREIGN KEY (site_id) REFERENCES sites(id) ON DELETE SET NULL;
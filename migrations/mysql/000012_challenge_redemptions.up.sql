@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS challenge_redemptions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    token_hash CHAR(64) NOT NULL,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME,
+    UNIQUE KEY idx_challenge_redemptions_token_hash (token_hash),
+    INDEX idx_challenge_redemptions_expires_at (expires_at)
+);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS post_vote_events (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    post_id INT NOT NULL,
+    user_id INT NOT NULL,
+    created_at DATETIME,
+    FOREIGN KEY(post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_post_vote_events_post_user ON post_vote_events(post_id, user_id);
+CREATE INDEX idx_post_vote_events_user_created ON post_vote_events(user_id, created_at);
+
+INSERT INTO post_vote_events (post_id, user_id, created_at)
+SELECT post_id, user_id, created_at FROM post_votes;
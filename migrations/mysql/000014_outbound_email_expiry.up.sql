@@ -0,0 +1 @@
+ALTER TABLE outbound_emails ADD COLUMN expires_at DATETIME NULL;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS feedbacks;
+
+DROP TABLE IF EXISTS magic_links;
+
+DROP TABLE IF EXISTS users;
\ No newline at end of file
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS feedback_tags;
+
+DROP TABLE IF EXISTS tags;
+
+DROP INDEX IF EXISTS idx_feedbacks_created_at;
+
+DROP INDEX IF EXISTS idx_feedbacks_status;
+
+ALTER TABLE feedbacks
+    DROP COLUMN updated_at,
+    DROP COLUMN status;
+
+ALTER TABLE users DROP COLUMN role;
\ No newline at end of file
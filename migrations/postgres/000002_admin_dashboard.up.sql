@@ -0,0 +1,21 @@
+ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user';
+
+ALTER TABLE feedbacks
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'new',
+    ADD COLUMN updated_at TIMESTAMPTZ;
+
+CREATE INDEX idx_feedbacks_status ON feedbacks(status);
+
+CREATE INDEX idx_feedbacks_created_at ON feedbacks(created_at);
+
+CREATE TABLE IF NOT EXISTS tags (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS feedback_tags (
+    feedback_id INT NOT NULL REFERENCES feedbacks(id) ON DELETE CASCADE,
+    tag_id INT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY(feedback_id, tag_id)
+);
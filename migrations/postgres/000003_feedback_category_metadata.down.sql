@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_feedbacks_category;
+
+ALTER TABLE feedbacks
+    DROP COLUMN metadata,
+    DROP COLUMN category;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE feedbacks
+    ADD COLUMN category VARCHAR(50) NOT NULL DEFAULT 'other',
+    ADD COLUMN metadata JSONB NULL;
+
+CREATE INDEX idx_feedbacks_category ON feedbacks(category);
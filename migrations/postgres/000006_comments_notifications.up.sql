@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS comments (
+    id SERIAL PRIMARY KEY,
+    feedback_id INT NOT NULL REFERENCES feedbacks(id) ON DELETE CASCADE,
+    author_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_comments_feedback_id ON comments(feedback_id);
+
+ALTER TABLE users
+    ADD COLUMN notify_on_reply BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN notify_on_status BOOLEAN NOT NULL DEFAULT TRUE;
+
+ALTER TABLE outbound_emails ADD COLUMN headers JSONB NULL;
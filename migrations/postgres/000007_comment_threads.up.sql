@@ -0,0 +1,13 @@
+ALTER TABLE comments
+    ADD COLUMN visibility VARCHAR(20) NOT NULL DEFAULT 'public',
+    ADD COLUMN edited_at TIMESTAMPTZ NULL;
+
+CREATE TABLE IF NOT EXISTS comment_edits (
+    id SERIAL PRIMARY KEY,
+    comment_id INT NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    editor_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_comment_edits_comment_id ON comment_edits(comment_id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS post_comments;
+DROP TABLE IF EXISTS post_subscriptions;
+DROP TABLE IF EXISTS post_votes;
+DROP TABLE IF EXISTS posts;
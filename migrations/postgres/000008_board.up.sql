@@ -0,0 +1,42 @@
+CREATE TABLE IF NOT EXISTS posts (
+    id SERIAL PRIMARY KEY,
+    feedback_id INT NOT NULL REFERENCES feedbacks(id) ON DELETE CASCADE,
+    title VARCHAR(200) NOT NULL,
+    body TEXT NOT NULL,
+    published_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_posts_feedback_id ON posts(feedback_id);
+CREATE INDEX idx_posts_published_at ON posts(published_at);
+
+CREATE TABLE IF NOT EXISTS post_votes (
+    id SERIAL PRIMARY KEY,
+    post_id INT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_post_votes_post_user ON post_votes(post_id, user_id);
+CREATE INDEX idx_post_votes_user_created ON post_votes(user_id, created_at);
+
+CREATE TABLE IF NOT EXISTS post_subscriptions (
+    id SERIAL PRIMARY KEY,
+    post_id INT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_post_subscriptions_post_user ON post_subscriptions(post_id, user_id);
+
+CREATE TABLE IF NOT EXISTS post_comments (
+    id SERIAL PRIMARY KEY,
+    post_id INT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    author_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_post_comments_post_id ON post_comments(post_id);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS releases (
+    id SERIAL PRIMARY KEY,
+    version VARCHAR(50) NOT NULL,
+    title VARCHAR(200) NOT NULL,
+    notes TEXT NOT NULL,
+    published_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_releases_version ON releases(version);
+CREATE INDEX idx_releases_published_at ON releases(published_at);
+
+CREATE TABLE IF NOT EXISTS release_feedback (
+    release_id INT NOT NULL REFERENCES releases(id) ON DELETE CASCADE,
+    feedback_id INT NOT NULL REFERENCES feedbacks(id) ON DELETE CASCADE,
+    PRIMARY KEY(release_id, feedback_id)
+);
@@ -0,0 +1,8 @@
+DELETE FROM feedbacks WHERE user_id IS NULL;
+
+DROP INDEX IF EXISTS idx_feedbacks_moderation;
+
+ALTER TABLE feedbacks
+    DROP COLUMN moderation,
+    DROP COLUMN guest_email,
+    ALTER COLUMN user_id SET NOT NULL;
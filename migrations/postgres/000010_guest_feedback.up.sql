@@ -0,0 +1,6 @@
+ALTER TABLE feedbacks
+    ALTER COLUMN user_id DROP NOT NULL,
+    ADD COLUMN guest_email VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN moderation VARCHAR(20) NOT NULL DEFAULT 'approved';
+
+CREATE INDEX idx_feedbacks_moderation ON feedbacks(moderation);
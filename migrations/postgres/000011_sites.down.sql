@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_feedbacks_site_id;
+
+ALTER TABLE feedbacks DROP COLUMN site_id;
+
+DROP TABLE IF EXISTS sites;
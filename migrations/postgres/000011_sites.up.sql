@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS sites (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    public_key VARCHAR(64) NOT NULL,
+    allowed_origins TEXT NOT NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_sites_public_key ON sites(public_key);
+
+ALTER TABLE feedbacks
+    ADD COLUMN site_id INT NULL REFERENCES sites(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_feedbacks_site_id ON feedbacks(site_id);
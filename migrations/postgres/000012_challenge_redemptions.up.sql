@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS challenge_redemptions (
+    id SERIAL PRIMARY KEY,
+    token_hash CHAR(64) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_challenge_redemptions_token_hash ON challenge_redemptions(token_hash);
+CREATE INDEX idx_challenge_redemptions_expires_at ON challenge_redemptions(expires_at);
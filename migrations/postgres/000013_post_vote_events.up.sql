@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS post_vote_events (
+    id SERIAL PRIMARY KEY,
+    post_id INT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_post_vote_events_post_user ON post_vote_events(post_id, user_id);
+CREATE INDEX idx_post_vote_events_user_created ON post_vote_events(user_id, created_at);
+
+INSERT INTO post_vote_events (post_id, user_id, created_at)
+SELECT post_id, user_id, created_at FROM post_votes;
@@ -1,17 +1,138 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+const (
+	FeedbackStatusNew        = "new"
+	FeedbackStatusInReview   = "in_review"
+	FeedbackStatusPlanned    = "planned"
+	FeedbackStatusInProgress = "in_progress"
+	FeedbackStatusDone       = "done"
+	FeedbackStatusDeclined   = "declined"
+)
+
+// FeedbackStatuses lists every valid feedback status in workflow order.
+var FeedbackStatuses = []string{
+	FeedbackStatusNew,
+	FeedbackStatusInReview,
+	FeedbackStatusPlanned,
+	FeedbackStatusInProgress,
+	FeedbackStatusDone,
+	FeedbackStatusDeclined,
+}
+
+// IsValidFeedbackStatus reports whether status is one of FeedbackStatuses.
+func IsValidFeedbackStatus(status string) bool {
+	for _, s := range FeedbackStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	FeedbackCategoryBug            = "bug"
+	FeedbackCategoryFeatureRequest = "feature_request"
+	FeedbackCategoryQuestion       = "question"
+	FeedbackCategoryPraise         = "praise"
+	FeedbackCategoryOther          = "other"
+)
+
+// FeedbackCategories lists every valid feedback category.
+var FeedbackCategories = []string{
+	FeedbackCategoryBug,
+	FeedbackCategoryFeatureRequest,
+	FeedbackCategoryQuestion,
+	FeedbackCategoryPraise,
+	FeedbackCategoryOther,
+}
+
+// IsValidFeedbackCategory reports whether category is one of FeedbackCategories.
+func IsValidFeedbackCategory(category string) bool {
+	for _, c := range FeedbackCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	EmailStatusQueued = "queued"
+	EmailStatusSent   = "sent"
+	EmailStatusFailed = "failed"
+)
+
+// EmailStatuses lists every outbound email status.
+var EmailStatuses = []string{
+	EmailStatusQueued,
+	EmailStatusSent,
+	EmailStatusFailed,
+}
+
+// Metadata holds free-form client context (platform, app version, device...)
+// and is stored as a JSON column.
+type Metadata map[string]string
+
+func (m Metadata) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (m *Metadata) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*m = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported metadata type %T", value)
+	}
+	return json.Unmarshal(data, m)
+}
+
+func (Metadata) GormDataType() string {
+	return "json"
+}
+
 type User struct {
-	ID        uint           `gorm:"primaryKey" json:"id"`
-	Email     string         `gorm:"uniqueIndex;not null" json:"email"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	Email          string         `gorm:"uniqueIndex;not null" json:"email"`
+	Role           string         `gorm:"type:varchar(20);not null;default:user" json:"role"`
+	Locale         string         `gorm:"type:varchar(10);not null;default:''" json:"locale"`
+	NotifyOnReply  bool           `gorm:"not null;default:true" json:"notify_on_reply"`
+	NotifyOnStatus bool           `gorm:"not null;default:true" json:"notify_on_status"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
 }
 
 type MagicLink struct {
@@ -23,9 +144,210 @@ type MagicLink struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+const (
+	ModerationApproved = "approved"
+	ModerationPending  = "pending"
+)
+
+// Feedback is a submission. Guest feedback has no UserID; it may carry an
+// unverified GuestEmail for staff to follow up on and waits in moderation
+// until staff approve it. SiteID is set for feedback sent through the
+// widget embedded on that site.
 type Feedback struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     *uint     `gorm:"index" json:"user_id"`
+	GuestEmail string    `gorm:"type:varchar(255);not null;default:''" json:"-"`
+	Content    string    `gorm:"type:text;not null" json:"content"`
+	Status     string    `gorm:"type:varchar(20);index;not null;default:new" json:"status"`
+	Category   string    `gorm:"type:varchar(50);index;not null;default:other" json:"category"`
+	Moderation string    `gorm:"type:varchar(20);index;not null;default:approved" json:"-"`
+	SiteID     *uint     `gorm:"index" json:"site_id,omitempty"`
+	Metadata   Metadata  `json:"metadata,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	User       *User     `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Site       *Site     `gorm:"foreignKey:SiteID" json:"-"`
+	Tags       []Tag     `gorm:"many2many:feedback_tags;" json:"tags,omitempty"`
+	Comments   []Comment `gorm:"foreignKey:FeedbackID" json:"comments,omitempty"`
+}
+
+// SubmitterID returns the ID of the user who submitted f, or 0 for guest
+// feedback.
+func (f *Feedback) SubmitterID() uint {
+	if f.UserID == nil {
+		return 0
+	}
+	return *f.UserID
+}
+
+// Site is a web property that embeds the feedback widget. PublicKey goes in
+// the embed code and identifies the site; it is not a secret. The widget
+// only answers browsers on one of AllowedOrigins, stored one per line.
+type Site struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	Name           string    `gorm:"type:varchar(100);not null" json:"name"`
+	PublicKey      string    `gorm:"type:varchar(64);uniqueIndex;not null" json:"public_key"`
+	AllowedOrigins string    `gorm:"type:text;not null" json:"-"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ChallengeRedemption marks a guest challenge as used. TokenHash is the hex
+// SHA-256 of the challenge token and is unique, so a token can be redeemed
+// once across all instances. Rows may be deleted once ExpiresAt has passed.
+type ChallengeRedemption struct {
+	ID        uint      `gorm:"primaryKey"`
+	TokenHash string    `gorm:"type:char(64);uniqueIndex;not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+}
+
+// Origins returns the allowed origins of s.
+func (s *Site) Origins() []string {
+	return strings.Fields(s.AllowedOrigins)
+}
+
+// AllowsOrigin reports whether origin, as sent in a browser's Origin
+// header, is one of the allowed origins of s.
+func (s *Site) AllowsOrigin(origin string) bool {
+	for _, allowed := range s.Origins() {
+		if strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	CommentVisibilityPublic   = "public"
+	CommentVisibilityInternal = "internal"
+)
+
+// Comment is a message in the conversation on a feedback item. Internal
+// comments are notes between staff and never shown to the submitter.
+type Comment struct {
+	ID         uint          `gorm:"primaryKey" json:"id"`
+	FeedbackID uint          `gorm:"index;not null" json:"feedback_id"`
+	AuthorID   uint          `gorm:"not null" json:"author_id"`
+	Body       string        `gorm:"type:text;not null" json:"body"`
+	Visibility string        `gorm:"type:varchar(20);not null;default:public" json:"visibility"`
+	EditedAt   *time.Time    `json:"edited_at,omitempty"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+	Author     *User         `gorm:"foreignKey:AuthorID" json:"-"`
+	Edits      []CommentEdit `gorm:"foreignKey:CommentID" json:"-"`
+}
+
+// CommentEdit keeps the text a comment had before it was edited.
+type CommentEdit struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CommentID uint      `gorm:"index;not null" json:"comment_id"`
+	EditorID  uint      `gorm:"not null" json:"editor_id"`
+	Body      string    `gorm:"type:text;not null" json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	Editor    *User     `gorm:"foreignKey:EditorID" json:"-"`
+}
+
+// Post is feedback staff published on the public board. Title and Body are
+// written for the public and may differ from the original feedback, which
+// can contain private details. Unpublished posts keep their votes.
+type Post struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	FeedbackID  uint       `gorm:"uniqueIndex;not null" json:"-"`
+	Title       string     `gorm:"type:varchar(200);not null" json:"title"`
+	Body        string     `gorm:"type:text;not null" json:"body"`
+	PublishedAt *time.Time `gorm:"index" json:"published_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	Feedback    *Feedback  `gorm:"foreignKey:FeedbackID" json:"-"`
+
+	// Filled in by board queries.
+	Status       string `gorm:"->;-:migration" json:"status"`
+	Votes        int64  `gorm:"->;-:migration" json:"votes"`
+	CommentCount int64  `gorm:"->;-:migration" json:"comment_count"`
+}
+
+// PostVote is one user's upvote on a post.
+type PostVote struct {
+	ID        uint      `gorm:"primaryKey"`
+	PostID    uint      `gorm:"not null;uniqueIndex:idx_post_votes_post_user,priority:1"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_post_votes_post_user,priority:2;index:idx_post_votes_user_created,priority:1"`
+	CreatedAt time.Time `gorm:"index:idx_post_votes_user_created,priority:2"`
+}
+
+// PostVoteEvent logs every vote a user casts. Unlike PostVote it is kept
+// when the vote is withdrawn, so vote limits count unvote/revote cycles.
+type PostVoteEvent struct {
+	ID        uint      `gorm:"primaryKey"`
+	PostID    uint      `gorm:"not null;index:idx_post_vote_events_post_user,priority:1"`
+	UserID    uint      `gorm:"not null;index:idx_post_vote_events_post_user,priority:2;index:idx_post_vote_events_user_created,priority:1"`
+	CreatedAt time.Time `gorm:"index:idx_post_vote_events_user_created,priority:2"`
+}
+
+// PostSubscription asks for emails about new comments on, and status changes
+// of, a post.
+type PostSubscription struct {
+	ID        uint `gorm:"primaryKey"`
+	PostID    uint `gorm:"not null;uniqueIndex:idx_post_subscriptions_post_user,priority:1"`
+	UserID    uint `gorm:"not null;uniqueIndex:idx_post_subscriptions_post_user,priority:2"`
+	CreatedAt time.Time
+}
+
+// PostComment is a public comment on a board post. Unlike Comment it is
+// visible to everyone, so only the author's role is exposed.
+type PostComment struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
-	UserID    uint      `gorm:"index;not null" json:"user_id"`
-	Content   string    `gorm:"type:text;not null" json:"content"`
+	PostID    uint      `gorm:"index;not null" json:"post_id"`
+	AuthorID  uint      `gorm:"not null" json:"-"`
+	Body      string    `gorm:"type:text;not null" json:"body"`
 	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Author    *User     `gorm:"foreignKey:AuthorID" json:"-"`
+
+	// Staff is filled in by board queries.
+	Staff bool `gorm:"->;-:migration" json:"staff"`
+}
+
+// Release is a changelog entry. Feedback lists the items it shipped; when
+// a release is published they are marked done and their submitters and
+// board subscribers are told.
+type Release struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Version     string     `gorm:"type:varchar(50);uniqueIndex;not null" json:"version"`
+	Title       string     `gorm:"type:varchar(200);not null" json:"title"`
+	Notes       string     `gorm:"type:text;not null" json:"notes"`
+	PublishedAt *time.Time `gorm:"index" json:"published_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	Feedback    []Feedback `gorm:"many2many:release_feedback;" json:"-"`
+
+	// Posts are the published board posts of Feedback, filled in for the
+	// public changelog.
+	Posts []Post `gorm:"-" json:"posts"`
+}
+
+type Tag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"type:varchar(50);uniqueIndex;not null" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OutboundEmail is a message waiting in, or processed by, the email queue.
+// Bodies are cleared once the message is sent since they may contain login
+// links.
+type OutboundEmail struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	Recipient     string     `gorm:"type:varchar(255);not null" json:"recipient"`
+	Subject       string     `gorm:"type:varchar(255);not null" json:"subject"`
+	TextBody      string     `gorm:"type:text" json:"-"`
+	HTMLBody      string     `gorm:"type:text" json:"-"`
+	Headers       Metadata   `json:"-"`
+	Status        string     `gorm:"type:varchar(20);not null;default:queued;index:idx_outbound_emails_status_next,priority:1" json:"status"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	LastError     string     `gorm:"type:text" json:"last_error,omitempty"`
+	NextAttemptAt time.Time  `gorm:"not null;index:idx_outbound_emails_status_next,priority:2" json:"next_attempt_at"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
 }
@@ -0,0 +1,49 @@
+package openapi
+
+import (
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+)
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+
+<head>
+    <meta charset="UTF-8" />
+    <title>Feedback App API</title>
+    <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css" />
+</head>
+
+<body>
+    <div id="swagger-ui"></div>
+    <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+    <script>
+        window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });
+    </script>
+</body>
+
+</html>`
+
+// SpecHandler serves the document at /openapi.json.
+func SpecHandler(doc *openapi3.T) (gin.HandlerFunc, error) {
+	body, err := JSON(doc)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+	}, nil
+}
+
+// docsContentSecurityPolicy lets the Swagger UI page load its assets from
+// unpkg and run its inline setup script.
+const docsContentSecurityPolicy = "default-src 'self'; script-src 'self' 'unsafe-inline' https://unpkg.com; " +
+	"style-src 'self' 'unsafe-inline' https://unpkg.com; img-src 'self' data:; frame-ancestors 'none'"
+
+// DocsHandler serves a Swagger UI page pointed at /openapi.json.
+func DocsHandler(c *gin.Context) {
+	c.Header("Content-Security-Policy", docsContentSecurityPolicy)
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
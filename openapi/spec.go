@@ -0,0 +1,32 @@
+// Package openapi embeds the OpenAPI 3 document describing every route of
+// cmd/server and exposes handlers to serve and enforce it.
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+//go:embed openapi.yaml
+var specYAML []byte
+
+// Load parses and validates the embedded OpenAPI document.
+func Load() (*openapi3.T, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(specYAML)
+	if err != nil {
+		return nil, err
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// JSON returns the document encoded as JSON.
+func JSON(doc *openapi3.T) ([]byte, error) {
+	return json.Marshal(doc)
+}
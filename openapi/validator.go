@@ -0,0 +1,129 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"feedback-app/middleware"
+	"feedback-app/services"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+)
+
+// Validator checks requests and responses against the OpenAPI document.
+// Requests that do not match the document are rejected with 400; responses
+// that do not match are logged, since the client already depends on them.
+// Errors attached by later handlers are rendered before the response is
+// validated, so the validator may sit anywhere after middleware.ErrorHandler.
+type Validator struct {
+	router routers.Router
+}
+
+func init() {
+	// Raw messages posted to /inbound/email are validated as opaque strings,
+	// as are the pages and scripts served to browsers.
+	openapi3filter.RegisterBodyDecoder("message/rfc822", openapi3filter.FileBodyDecoder)
+	openapi3filter.RegisterBodyDecoder("text/html", openapi3filter.PlainBodyDecoder)
+	openapi3filter.RegisterBodyDecoder("text/javascript", openapi3filter.PlainBodyDecoder)
+}
+
+func NewValidator(doc *openapi3.T) (*Validator, error) {
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &Validator{router: router}, nil
+}
+
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Middleware validates requests to operations without security
+// requirements. Register it on the router; operations that require
+// authentication are left to Authenticated.
+func (v *Validator) Middleware() gin.HandlerFunc {
+	return v.middleware(false)
+}
+
+// Authenticated validates requests to operations with security
+// requirements. Register it after the authentication middleware of their
+// route group, so that unauthenticated requests are answered with 401 rather
+// than 400.
+func (v *Validator) Authenticated() gin.HandlerFunc {
+	return v.middleware(true)
+}
+
+func (v *Validator) middleware(authenticated bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route, pathParams, err := v.router.FindRoute(c.Request)
+		if err != nil || requiresAuthentication(route) != authenticated {
+			// Undocumented routes are left to gin (404 or otherwise), and
+			// operations of the other kind to the other middleware.
+			c.Next()
+			return
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				// Authentication is enforced by our own middleware.
+				AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			},
+		}
+		if err := openapi3filter.ValidateRequest(c.Request.Context(), input); err != nil {
+			middleware.WriteProblem(c, services.Invalid("Request does not match API specification"))
+			log.Printf("OpenAPI request validation failed for %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+		middleware.WritePendingError(c)
+
+		if err := v.ValidateResponse(c.Request.Context(), input, recorder.Status(), recorder.Header(), recorder.body.Bytes()); err != nil {
+			log.Printf("OpenAPI response validation failed for %s %s (%d): %v", c.Request.Method, c.Request.URL.Path, recorder.Status(), err)
+		}
+	}
+}
+
+// requiresAuthentication reports whether the operation of route, or the
+// document when the operation does not say, has security requirements.
+func requiresAuthentication(route *routers.Route) bool {
+	security := route.Operation.Security
+	if security == nil {
+		security = &route.Spec.Security
+	}
+	return len(*security) > 0
+}
+
+// ValidateResponse checks a recorded response against the operation matched
+// by input.
+func (v *Validator) ValidateResponse(ctx context.Context, input *openapi3filter.RequestValidationInput, status int, header http.Header, body []byte) error {
+	return openapi3filter.ValidateResponse(ctx, &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 status,
+		Header:                 header,
+		Body:                   io.NopCloser(bytes.NewReader(body)),
+	})
+}
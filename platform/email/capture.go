@@ -0,0 +1,82 @@
+package email
+
+import (
+	"sync"
+	"time"
+)
+
+// maxCaptured bounds the memory used by CaptureSink; older messages are
+// dropped first.
+const maxCaptured = 500
+
+// CapturedEmail is a message recorded by CaptureSink.
+type CapturedEmail struct {
+	ID      int       `json:"id"`
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	Text    string    `json:"text"`
+	HTML    string    `json:"html"`
+	Raw     string    `json:"raw"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+// CaptureSink keeps sent messages in memory so end-to-end tests can read
+// login links back through the /dev/emails endpoint instead of a real inbox.
+type CaptureSink struct {
+	mu       sync.Mutex
+	from     string
+	nextID   int
+	messages []CapturedEmail
+}
+
+func NewCaptureSink(from string) *CaptureSink {
+	return &CaptureSink{from: from, nextID: 1}
+}
+
+func (s *CaptureSink) Send(msg Message) error {
+	now := time.Now()
+	raw, err := msg.Build(s.from, now)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages = append(s.messages, CapturedEmail{
+		ID:      s.nextID,
+		To:      msg.To,
+		Subject: msg.Subject,
+		Text:    msg.Text,
+		HTML:    msg.HTML,
+		Raw:     string(raw),
+		SentAt:  now,
+	})
+	s.nextID++
+	if len(s.messages) > maxCaptured {
+		s.messages = s.messages[len(s.messages)-maxCaptured:]
+	}
+	return nil
+}
+
+// Messages returns the captured messages, oldest first, optionally only
+// those sent to the given address.
+func (s *CaptureSink) Messages(to string) []CapturedEmail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]CapturedEmail, 0, len(s.messages))
+	for _, msg := range s.messages {
+		if to == "" || msg.To == to {
+			result = append(result, msg)
+		}
+	}
+	return result
+}
+
+// Reset discards every captured message.
+func (s *CaptureSink) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = nil
+}
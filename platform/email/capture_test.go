@@ -0,0 +1,44 @@
+package email_test
+
+import (
+	"strings"
+	"testing"
+
+	"feedback-app/platform/email"
+)
+
+func TestCaptureSink(t *testing.T) {
+	sink := email.NewCaptureSink("Feedback <noreply@example.com>")
+
+	for _, msg := range []email.Message{
+		{To: "ada@example.com", Subject: "Your login link", Text: "Open the link", HTML: "<p>Open the link</p>"},
+		{To: "bob@example.com", Subject: "New reply", Text: "Someone replied"},
+	} {
+		if err := sink.Send(msg); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+
+	messages := sink.Messages("ada@example.com")
+	if len(messages) != 1 {
+		t.Fatalf("messages to ada = %d, want 1", len(messages))
+	}
+	got := messages[0]
+	if got.ID != 1 || got.To != "ada@example.com" || got.Subject != "Your login link" ||
+		got.Text != "Open the link" || got.HTML != "<p>Open the link</p>" || got.SentAt.IsZero() {
+		t.Errorf("captured = %+v, want the message as sent", got)
+	}
+	for _, header := range []string{"From: \"Feedback\" <noreply@example.com>", "To: <ada@example.com>", "Subject: Your login link"} {
+		if !strings.Contains(got.Raw, header) {
+			t.Errorf("raw message lacks %q:\n%s", header, got.Raw)
+		}
+	}
+
+	if all := sink.Messages(""); len(all) != 2 || all[1].ID != 2 {
+		t.Errorf("all messages = %+v, want both in order", all)
+	}
+	sink.Reset()
+	if all := sink.Messages(""); len(all) != 0 {
+		t.Errorf("messages after Reset = %d, want 0", len(all))
+	}
+}
@@ -1,44 +1,27 @@
 package email
 
-import (
-	"feedback-app/config"
-	"fmt"
-	"net/smtp"
-)
+import "errors"
 
 type Client interface {
-	Send(to string, subject string, body string) error
+	Send(msg Message) error
 }
 
-type SMTPClient struct {
-	cfg config.SMTPConfig
+// PermanentError is a delivery failure that retrying cannot fix, such as a
+// rejected API key or recipient.
+type PermanentError struct {
+	Err error
 }
 
-func NewSMTPClient(cfg config.SMTPConfig) *SMTPClient {
-	return &SMTPClient{cfg: cfg}
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
 }
 
-func (c *SMTPClient) Send(to string, subject string, body string) error {
-	var auth smtp.Auth
-	if c.cfg.User != "" {
-		auth = smtp.PlainAuth("", c.cfg.User, c.cfg.Password, c.cfg.Host)
-	}
-
-	headers := map[string]string{
-		"From":         c.cfg.From,
-		"To":           to,
-		"Subject":      subject,
-		"MIME-Version": "1.0",
-		"Content-Type": "text/html; charset=\"UTF-8\"",
-	}
-
-	msg := ""
-	for k, v := range headers {
-		msg += fmt.Sprintf("%s: %s\r\n", k, v)
-	}
-	msg += "\r\n" + body
-
-	addr := fmt.Sprintf("%s:%s", c.cfg.Host, c.cfg.Port)
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
 
-	return smtp.SendMail(addr, auth, c.cfg.From, []string{to}, []byte(msg))
+// IsPermanent reports whether err, or an error it wraps, is a PermanentError.
+func IsPermanent(err error) bool {
+	var permanent *PermanentError
+	return errors.As(err, &permanent)
 }
@@ -0,0 +1,64 @@
+package email
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileSink writes every message as an .eml file into a directory instead of
+// sending it. Open the files with any mail client to check the rendering.
+type FileSink struct {
+	dir  string
+	from string
+}
+
+func NewFileSink(dir string, from string) (*FileSink, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create email directory: %w", err)
+	}
+	return &FileSink{dir: dir, from: from}, nil
+}
+
+func (s *FileSink) Send(msg Message) error {
+	now := time.Now()
+	raw, err := msg.Build(s.from, now)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(s.dir, now.Format("20060102-150405")+"-*.eml")
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(raw); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// ConsoleSink prints every message to a writer, normally stdout.
+type ConsoleSink struct {
+	mu   sync.Mutex
+	w    io.Writer
+	from string
+}
+
+func NewConsoleSink(w io.Writer, from string) *ConsoleSink {
+	return &ConsoleSink{w: w, from: from}
+}
+
+func (s *ConsoleSink) Send(msg Message) error {
+	raw, err := msg.Build(s.from, time.Now())
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = fmt.Fprintf(s.w, "----- email to %s -----\n%s\n----- end of email -----\n", msg.To, raw)
+	return err
+}
@@ -0,0 +1,87 @@
+package email
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"time"
+)
+
+// HTTPClient sends mail through a transactional email provider's JSON API
+// (Sendgrid, Mailgun, Postmark and similar, usually behind a small adapter).
+// It POSTs the message to the configured URL with the API key as a bearer
+// token and treats any 2xx response as accepted. Other 4xx responses, except
+// 408 and 429, are PermanentErrors; 5xx, timeouts and network errors are
+// worth retrying.
+type HTTPClient struct {
+	url    string
+	apiKey string
+	from   string
+	client *http.Client
+}
+
+type httpMessage struct {
+	From    string            `json:"from"`
+	To      []string          `json:"to"`
+	Subject string            `json:"subject"`
+	Text    string            `json:"text,omitempty"`
+	HTML    string            `json:"html,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+func NewHTTPClient(url string, apiKey string, from string, timeout time.Duration) *HTTPClient {
+	return &HTTPClient{
+		url:    url,
+		apiKey: apiKey,
+		from:   from,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (c *HTTPClient) Send(msg Message) error {
+	recipient, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid to address %q: %w", msg.To, err)
+	}
+
+	payload, err := json.Marshal(httpMessage{
+		From:    c.from,
+		To:      []string{recipient.Address},
+		Subject: msg.Subject,
+		Text:    msg.Text,
+		HTML:    msg.HTML,
+		Headers: msg.Headers,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("email API returned %s: %s", resp.Status, bytes.TrimSpace(body))
+	if resp.StatusCode >= 400 && resp.StatusCode <= 499 &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return &PermanentError{Err: err}
+	}
+	return err
+}
@@ -0,0 +1,124 @@
+package email_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"feedback-app/platform/email"
+)
+
+func TestHTTPClientSend(t *testing.T) {
+	var (
+		gotAuth        string
+		gotContentType string
+		gotPayload     map[string]interface{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		gotAuth = r.Header.Get("Authorization")
+		gotContentType = r.Header.Get("Content-Type")
+		if err := json.NewDecoder(r.Body).Decode(&gotPayload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	client := email.NewHTTPClient(server.URL, "api-key", "noreply@example.com", time.Second)
+	err := client.Send(email.Message{
+		To:      "Ada <ada@example.com>",
+		Subject: "Your login link",
+		Text:    "Open the link",
+		HTML:    "<p>Open the link</p>",
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if gotAuth != "Bearer api-key" {
+		t.Errorf("Authorization = %q, want the API key as a bearer token", gotAuth)
+	}
+	if gotContentType != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", gotContentType)
+	}
+	want := map[string]interface{}{
+		"from":    "noreply@example.com",
+		"to":      []interface{}{"ada@example.com"},
+		"subject": "Your login link",
+		"text":    "Open the link",
+		"html":    "<p>Open the link</p>",
+	}
+	if len(gotPayload) != len(want) {
+		t.Errorf("payload = %v, want exactly the fields of %v", gotPayload, want)
+	}
+	for key, value := range want {
+		got, _ := json.Marshal(gotPayload[key])
+		expected, _ := json.Marshal(value)
+		if string(got) != string(expected) {
+			t.Errorf("payload %s = %s, want %s", key, got, expected)
+		}
+	}
+}
+
+func TestHTTPClientErrorClassification(t *testing.T) {
+	tests := []struct {
+		status    int
+		wantErr   bool
+		permanent bool
+	}{
+		{http.StatusOK, false, false},
+		{http.StatusAccepted, false, false},
+		{http.StatusBadRequest, true, true},
+		{http.StatusUnauthorized, true, true},
+		{http.StatusUnprocessableEntity, true, true},
+		{http.StatusRequestTimeout, true, false},
+		{http.StatusTooManyRequests, true, false},
+		{http.StatusInternalServerError, true, false},
+		{http.StatusServiceUnavailable, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`{"error": "nope"}`))
+			}))
+			defer server.Close()
+
+			client := email.NewHTTPClient(server.URL, "api-key", "noreply@example.com", time.Second)
+			err := client.Send(email.Message{To: "ada@example.com", Subject: "Hi", Text: "Hello"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send = %v, want error %v", err, tt.wantErr)
+			}
+			if email.IsPermanent(err) != tt.permanent {
+				t.Errorf("IsPermanent(%v) = %v, want %v", err, !tt.permanent, tt.permanent)
+			}
+		})
+	}
+}
+
+func TestHTTPClientTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := email.NewHTTPClient(server.URL, "", "noreply@example.com", 50*time.Millisecond)
+	start := time.Now()
+	err := client.Send(email.Message{To: "ada@example.com", Subject: "Hi", Text: "Hello"})
+	if err == nil {
+		t.Fatal("Send succeeded although the API did not answer")
+	}
+	if email.IsPermanent(err) {
+		t.Errorf("timeout %v is permanent, want it retried", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Send took %s, want it to give up after the timeout", elapsed)
+	}
+}
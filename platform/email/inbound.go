@@ -0,0 +1,269 @@
+package email
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// maxMIMEDepth bounds how deeply nested multipart bodies are searched.
+const maxMIMEDepth = 5
+
+// InboundMessage is a received email reduced to what replies need.
+type InboundMessage struct {
+	From    string
+	To      []string
+	Subject string
+	// Text is the decoded plain-text body, or text extracted from the HTML
+	// body when the message has no plain-text part.
+	Text string
+	// Automatic is set for auto-replies and bulk mail, which must not be
+	// treated as answers and could otherwise start mail loops.
+	Automatic bool
+}
+
+// ParseInbound reads a raw RFC 5322 message. Recipients come from To and Cc
+// as well as the envelope headers inbound mail services add, since the
+// reply address may only appear there when the user replied via Bcc.
+func ParseInbound(r io.Reader) (*InboundMessage, error) {
+	msg, err := mail.ReadMessage(bufio.NewReader(r))
+	if err != nil {
+		return nil, fmt.Errorf("read message: %w", err)
+	}
+
+	from, err := mail.ParseAddress(msg.Header.Get("From"))
+	if err != nil {
+		return nil, fmt.Errorf("parse From: %w", err)
+	}
+
+	decoder := new(mime.WordDecoder)
+	subject, err := decoder.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+
+	inbound := &InboundMessage{
+		From:      from.Address,
+		Subject:   subject,
+		Automatic: isAutomatic(msg.Header),
+	}
+	for _, key := range []string{"To", "Cc", "Delivered-To", "X-Original-To", "Envelope-To"} {
+		for _, value := range msg.Header[key] {
+			addresses, err := mail.ParseAddressList(value)
+			if err != nil {
+				continue
+			}
+			for _, address := range addresses {
+				inbound.To = append(inbound.To, address.Address)
+			}
+		}
+	}
+
+	plain, htmlBody, err := textParts(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body, 0)
+	if err != nil {
+		return nil, err
+	}
+	if plain != "" {
+		inbound.Text = plain
+	} else {
+		inbound.Text = htmlToText(htmlBody)
+	}
+	return inbound, nil
+}
+
+func isAutomatic(header mail.Header) bool {
+	if value := strings.ToLower(header.Get("Auto-Submitted")); value != "" && value != "no" {
+		return true
+	}
+	switch strings.ToLower(header.Get("Precedence")) {
+	case "bulk", "junk", "list", "auto_reply":
+		return true
+	}
+	return header.Get("X-Autoreply") != "" || header.Get("X-Autorespond") != ""
+}
+
+// textParts returns the first text/plain and the first text/html body found
+// in a (possibly multipart) body. Attachments are skipped.
+func textParts(contentType string, encoding string, body io.Reader, depth int) (string, string, error) {
+	if contentType == "" {
+		contentType = "text/plain"
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", "", fmt.Errorf("parse Content-Type: %w", err)
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= maxMIMEDepth {
+			return "", "", errors.New("multipart nesting too deep")
+		}
+		var plain, htmlBody string
+		parts := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := parts.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return "", "", fmt.Errorf("read multipart body: %w", err)
+			}
+			if disposition, _, _ := mime.ParseMediaType(part.Header.Get("Content-Disposition")); disposition == "attachment" {
+				continue
+			}
+			// NextPart already decodes quoted-printable parts.
+			p, h, err := textParts(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part, depth+1)
+			if err != nil {
+				return "", "", err
+			}
+			if plain == "" {
+				plain = p
+			}
+			if htmlBody == "" {
+				htmlBody = h
+			}
+		}
+		return plain, htmlBody, nil
+	}
+
+	if mediaType != "text/plain" && mediaType != "text/html" {
+		return "", "", nil
+	}
+
+	content, err := io.ReadAll(decodeTransfer(encoding, body))
+	if err != nil {
+		return "", "", fmt.Errorf("decode body: %w", err)
+	}
+	text := decodeCharset(params["charset"], content)
+	if mediaType == "text/html" {
+		return "", text, nil
+	}
+	return text, "", nil
+}
+
+func decodeTransfer(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, body)
+	default:
+		return body
+	}
+}
+
+// decodeCharset converts Latin-1 bodies to UTF-8. Other charsets are passed
+// through; practically every mail client sends UTF-8 today.
+func decodeCharset(charset string, content []byte) string {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "windows-1252":
+		runes := make([]rune, len(content))
+		for i, b := range content {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	default:
+		return string(content)
+	}
+}
+
+var (
+	htmlDropPattern  = regexp.MustCompile(`(?is)<(head|style|script|blockquote)\b.*?</(head|style|script|blockquote)>`)
+	htmlBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|tr|h[1-6])>`)
+	htmlTagPattern   = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// htmlToText is a rough conversion for HTML-only replies. Quoted messages in
+// <blockquote> are dropped along with the markup.
+func htmlToText(body string) string {
+	body = htmlDropPattern.ReplaceAllString(body, "")
+	body = htmlBreakPattern.ReplaceAllString(body, "\n")
+	body = htmlTagPattern.ReplaceAllString(body, "")
+	return html.UnescapeString(body)
+}
+
+var (
+	// quoteHeaderPatterns match the line mail clients put above the quoted
+	// original, in the languages the app supports.
+	quoteHeaderPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^On\s.+\swrote:$`),
+		regexp.MustCompile(`^Am\s.+\sschrieb\s.*:$`),
+		regexp.MustCompile(`^El\s.+\sescribió:$`),
+		regexp.MustCompile(`^-{2,}\s*(Original Message|Ursprüngliche Nachricht|Mensaje original)\s*-{2,}$`),
+		regexp.MustCompile(`^_{10,}$`),
+	}
+	// outlookHeaderPattern matches the first line of the header block Outlook
+	// puts above the original message.
+	outlookHeaderPattern = regexp.MustCompile(`^\*?(From|Von|De):\*?\s`)
+	outlookDatePattern   = regexp.MustCompile(`^\*?(Sent|Date|Gesendet|Datum|Enviado|Fecha):\*?\s`)
+	mobileSignature      = regexp.MustCompile(`^(Sent from my .+|Von meinem .+ gesendet|Enviado desde mi .+)$`)
+)
+
+// StripQuoted returns only the new text of a reply: everything from the
+// quoted original or the signature on is removed, as are interleaved lines
+// starting with ">".
+func StripQuoted(text string) string {
+	lines := strings.Split(normalizeNewlines(text), "\r\n")
+
+	var kept []string
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if line == "-- " || trimmed == "--" || mobileSignature.MatchString(trimmed) {
+			break
+		}
+		if isQuoteHeader(trimmed) {
+			break
+		}
+		// Long headers such as "On Mon, 1 Jan 2024 at 10:00, Feedback App
+		// <reply+...>" are often wrapped before "wrote:".
+		if i+1 < len(lines) && isQuoteHeader(trimmed+" "+strings.TrimSpace(lines[i+1])) {
+			break
+		}
+		if outlookHeaderPattern.MatchString(trimmed) && i+1 < len(lines) && outlookDatePattern.MatchString(strings.TrimSpace(lines[i+1])) {
+			break
+		}
+		if strings.HasPrefix(trimmed, ">") {
+			continue
+		}
+		kept = append(kept, strings.TrimRight(line, " \t"))
+	}
+
+	return strings.TrimSpace(collapseBlankLines(kept))
+}
+
+func isQuoteHeader(line string) bool {
+	for _, pattern := range quoteHeaderPatterns {
+		if pattern.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// collapseBlankLines joins lines, keeping at most one blank line in a row,
+// which is what removing interleaved quotes tends to leave behind.
+func collapseBlankLines(lines []string) string {
+	var b strings.Builder
+	blank := false
+	for _, line := range lines {
+		if line == "" {
+			if blank {
+				continue
+			}
+			blank = true
+		} else {
+			blank = false
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
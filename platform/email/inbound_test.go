@@ -0,0 +1,197 @@
+package email_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"feedback-app/platform/email"
+)
+
+func TestParseInbound(t *testing.T) {
+	tests := []struct {
+		name      string
+		raw       string
+		to        []string
+		subject   string
+		text      string
+		automatic bool
+	}{
+		{
+			name: "plain text",
+			raw: "From: Ann <ann@example.com>\r\n" +
+				"To: reply+1.2.abc@mail.example.com\r\n" +
+				"Cc: Bob <bob@example.com>\r\n" +
+				"Subject: =?UTF-8?Q?Gr=C3=BC=C3=9Fe?=\r\n" +
+				"\r\n" +
+				"Thanks!\r\n",
+			to:      []string{"reply+1.2.abc@mail.example.com", "bob@example.com"},
+			subject: "Grüße",
+			text:    "Thanks!\r\n",
+		},
+		{
+			name: "envelope recipient",
+			raw: "From: ann@example.com\r\n" +
+				"To: undisclosed-recipients:;\r\n" +
+				"Delivered-To: reply+1.2.abc@mail.example.com\r\n" +
+				"\r\n" +
+				"Hi\r\n",
+			to:   []string{"reply+1.2.abc@mail.example.com"},
+			text: "Hi\r\n",
+		},
+		{
+			name: "multipart prefers plain text",
+			raw: "From: ann@example.com\r\n" +
+				"To: support@example.com\r\n" +
+				"Content-Type: multipart/alternative; boundary=b\r\n" +
+				"\r\n" +
+				"--b\r\n" +
+				"Content-Type: text/html\r\n" +
+				"\r\n" +
+				"<p>HTML</p>\r\n" +
+				"--b\r\n" +
+				"Content-Type: text/plain; charset=utf-8\r\n" +
+				"Content-Transfer-Encoding: quoted-printable\r\n" +
+				"\r\n" +
+				"Sch=C3=B6n\r\n" +
+				"--b--\r\n",
+			to:   []string{"support@example.com"},
+			text: "Schön",
+		},
+		{
+			name: "html only",
+			raw: "From: ann@example.com\r\n" +
+				"To: support@example.com\r\n" +
+				"Content-Type: text/html; charset=utf-8\r\n" +
+				"\r\n" +
+				"<html><head><style>p{}</style></head><body><p>Fish &amp; chips</p>Line<br>next" +
+				"<blockquote>quoted</blockquote></body></html>",
+			to:   []string{"support@example.com"},
+			text: "Fish & chips\nLine\nnext",
+		},
+		{
+			name: "attachments skipped",
+			raw: "From: ann@example.com\r\n" +
+				"To: support@example.com\r\n" +
+				"Content-Type: multipart/mixed; boundary=b\r\n" +
+				"\r\n" +
+				"--b\r\n" +
+				"Content-Type: text/plain\r\n" +
+				"Content-Disposition: attachment; filename=a.txt\r\n" +
+				"\r\n" +
+				"attached\r\n" +
+				"--b\r\n" +
+				"Content-Type: text/plain\r\n" +
+				"\r\n" +
+				"body\r\n" +
+				"--b--\r\n",
+			to:   []string{"support@example.com"},
+			text: "body",
+		},
+		{
+			name: "auto reply",
+			raw: "From: ann@example.com\r\n" +
+				"To: support@example.com\r\n" +
+				"Auto-Submitted: auto-replied\r\n" +
+				"\r\n" +
+				"Out of office\r\n",
+			to:        []string{"support@example.com"},
+			text:      "Out of office\r\n",
+			automatic: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := email.ParseInbound(strings.NewReader(tt.raw))
+			if err != nil {
+				t.Fatalf("ParseInbound: %v", err)
+			}
+			if !reflect.DeepEqual(msg.To, tt.to) {
+				t.Errorf("To = %q, want %q", msg.To, tt.to)
+			}
+			if msg.Subject != tt.subject {
+				t.Errorf("Subject = %q, want %q", msg.Subject, tt.subject)
+			}
+			if msg.Text != tt.text {
+				t.Errorf("Text = %q, want %q", msg.Text, tt.text)
+			}
+			if msg.Automatic != tt.automatic {
+				t.Errorf("Automatic = %v, want %v", msg.Automatic, tt.automatic)
+			}
+		})
+	}
+}
+
+func TestParseInboundRejectsMissingSender(t *testing.T) {
+	if _, err := email.ParseInbound(strings.NewReader("To: support@example.com\r\n\r\nHi\r\n")); err == nil {
+		t.Fatal("ParseInbound accepted a message without From")
+	}
+}
+
+func TestStripQuoted(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "gmail header",
+			text: "Sounds good.\n\nOn Mon, 1 Jan 2024 at 10:00, Feedback App <reply+1.2.x@example.com> wrote:\n> Original\n",
+			want: "Sounds good.",
+		},
+		{
+			name: "wrapped header",
+			text: "Yes\r\n\r\nOn Mon, 1 Jan 2024 at 10:00, Feedback App\r\n<reply+1.2.x@example.com> wrote:\r\n> Original\r\n",
+			want: "Yes",
+		},
+		{
+			name: "german header",
+			text: "Danke\n\nAm 01.01.2024 um 10:00 schrieb Feedback App <x@example.com>:\n> Original\n",
+			want: "Danke",
+		},
+		{
+			name: "interleaved quotes",
+			text: "> Does it crash?\nYes, always.\n\n> Which version?\n\n2.1\n",
+			want: "Yes, always.\n\n2.1",
+		},
+		{
+			name: "outlook separator",
+			text: "Fixed now.\n\n________________________________\nFrom: Feedback App\nSent: Monday\n",
+			want: "Fixed now.",
+		},
+		{
+			name: "outlook header block",
+			text: "Fixed now.\n\nFrom: Feedback App <noreply@example.com>\nSent: Monday, January 1, 2024\nTo: Ann\n",
+			want: "Fixed now.",
+		},
+		{
+			name: "original message",
+			text: "Ok\n-----Original Message-----\nHello\n",
+			want: "Ok",
+		},
+		{
+			name: "signature",
+			text: "Thanks\n-- \nAnn\n",
+			want: "Thanks",
+		},
+		{
+			name: "mobile signature",
+			text: "Thanks\n\nSent from my iPhone\n",
+			want: "Thanks",
+		},
+		{
+			name: "from line in text",
+			text: "From: my point of view it works.\nThanks\n",
+			want: "From: my point of view it works.\nThanks",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := email.StripQuoted(tt.text); got != tt.want {
+				t.Errorf("StripQuoted = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
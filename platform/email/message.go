@@ -0,0 +1,168 @@
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Message is an email with a plain-text body, an HTML body or both. When both
+// are set it is sent as multipart/alternative so clients can pick one.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	// Headers are extra headers such as List-Unsubscribe. They cannot
+	// replace the ones Build sets itself.
+	Headers map[string]string
+	// ExpiresAt, if set, is when the message becomes useless, such as the
+	// expiry of the login link it carries. Queues must not send it later.
+	ExpiresAt time.Time
+}
+
+// Build renders msg as an RFC 5322 message from the given sender. Headers are
+// written in a fixed order, non-ASCII header text is RFC 2047 encoded and
+// bodies are quoted-printable (RFC 2045) so long lines survive transport.
+func (msg Message) Build(from string, now time.Time) ([]byte, error) {
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address %q: %w", from, err)
+	}
+	recipient, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return nil, fmt.Errorf("invalid to address %q: %w", msg.To, err)
+	}
+	if msg.Text == "" && msg.HTML == "" {
+		return nil, fmt.Errorf("email to %s has no body", msg.To)
+	}
+
+	messageID, err := newMessageID(sender.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writeHeader(&buf, "From", sender.String())
+	writeHeader(&buf, "To", recipient.String())
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("utf-8", stripNewlines(msg.Subject)))
+	writeHeader(&buf, "Date", now.Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", messageID)
+	writeHeader(&buf, "MIME-Version", "1.0")
+	if err := writeExtraHeaders(&buf, msg.Headers); err != nil {
+		return nil, err
+	}
+
+	if msg.Text == "" || msg.HTML == "" {
+		contentType, body := "text/plain; charset=utf-8", msg.Text
+		if msg.HTML != "" {
+			contentType, body = "text/html; charset=utf-8", msg.HTML
+		}
+		writeHeader(&buf, "Content-Type", contentType)
+		writeHeader(&buf, "Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, body); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	parts := multipart.NewWriter(&buf)
+	writeHeader(&buf, "Content-Type", mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": parts.Boundary()}))
+	buf.WriteString("\r\n")
+
+	// Per RFC 2046 the preferred alternative comes last.
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(w, part.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeHeader(buf *bytes.Buffer, key, value string) {
+	fmt.Fprintf(buf, "%s: %s\r\n", key, stripNewlines(value))
+}
+
+// stripNewlines removes line breaks from a header value. Header values come
+// from configuration and user input; never let them inject additional
+// headers. Values are stripped before RFC 2047 encoding too, which would
+// otherwise carry the line breaks into the decoded text.
+func stripNewlines(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
+
+// reservedHeaders are set by Build and may not be overridden.
+var reservedHeaders = map[string]bool{
+	"From": true, "To": true, "Subject": true, "Date": true, "Message-Id": true,
+	"Mime-Version": true, "Content-Type": true, "Content-Transfer-Encoding": true,
+}
+
+func writeExtraHeaders(buf *bytes.Buffer, headers map[string]string) error {
+	values := make(map[string]string, len(headers))
+	names := make([]string, 0, len(headers))
+	for key, value := range headers {
+		name := textproto.CanonicalMIMEHeaderKey(key)
+		if _, ok := values[name]; ok || reservedHeaders[name] || strings.ContainsAny(name, ": \t\r\n") {
+			return fmt.Errorf("invalid extra header %q", key)
+		}
+		values[name] = value
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		writeHeader(buf, name, values[name])
+	}
+	return nil
+}
+
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(normalizeNewlines(body))); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+func normalizeNewlines(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(s, "\n", "\r\n")
+}
+
+func newMessageID(senderAddress string) (string, error) {
+	domain := "localhost"
+	if at := strings.LastIndex(senderAddress, "@"); at >= 0 {
+		domain = senderAddress[at+1:]
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(b), domain), nil
+}
@@ -0,0 +1,232 @@
+package email_test
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"feedback-app/platform/email"
+)
+
+const testFrom = "Feedback App <noreply@example.com>"
+
+var testDate = time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+
+// headerNames returns the header field names of raw in order.
+func headerNames(t *testing.T, raw []byte) []string {
+	t.Helper()
+	head, _, ok := bytes.Cut(raw, []byte("\r\n\r\n"))
+	if !ok {
+		t.Fatalf("message has no header/body separator:\n%s", raw)
+	}
+	var names []string
+	for _, line := range strings.Split(string(head), "\r\n") {
+		name, _, ok := strings.Cut(line, ":")
+		if !ok || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			t.Fatalf("malformed header line %q", line)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestMessageBuildHeaders(t *testing.T) {
+	msg := email.Message{
+		To:      "Ada <ada@example.com>",
+		Subject: "Your login link",
+		Text:    "Hello",
+		Headers: map[string]string{
+			"list-unsubscribe":      "<https://feedback.example.com/unsubscribe>",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	}
+	raw, err := msg.Build(testFrom, testDate)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	want := []string{"From", "To", "Subject", "Date", "Message-ID", "MIME-Version", "List-Unsubscribe", "List-Unsubscribe-Post", "Content-Type", "Content-Transfer-Encoding"}
+	if got := headerNames(t, raw); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("headers = %v, want %v", got, want)
+	}
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("parse built message: %v", err)
+	}
+	for key, value := range map[string]string{
+		"From":         `"Feedback App" <noreply@example.com>`,
+		"To":           `"Ada" <ada@example.com>`,
+		"Subject":      "Your login link",
+		"Date":         "Fri, 01 Mar 2024 09:30:00 +0000",
+		"Content-Type": "text/plain; charset=utf-8",
+	} {
+		if got := parsed.Header.Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+	if id := parsed.Header.Get("Message-ID"); !strings.HasPrefix(id, "<") || !strings.HasSuffix(id, "@example.com>") {
+		t.Errorf("Message-ID = %q, want <...@example.com>", id)
+	}
+}
+
+func TestMessageBuildEncodesSubject(t *testing.T) {
+	tests := []struct {
+		subject string
+		encoded bool
+	}{
+		{"Login to Feedback App", false},
+		{"Anmeldung bei Feedback App – Grüße", true},
+		{"Iniciar sesión", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.subject, func(t *testing.T) {
+			raw, err := email.Message{To: "ada@example.com", Subject: tt.subject, Text: "Hi"}.Build(testFrom, testDate)
+			if err != nil {
+				t.Fatalf("Build: %v", err)
+			}
+			parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+			if err != nil {
+				t.Fatalf("parse built message: %v", err)
+			}
+			header := parsed.Header.Get("Subject")
+			if got := strings.HasPrefix(header, "=?utf-8?q?"); got != tt.encoded {
+				t.Fatalf("Subject header %q encoded = %v, want %v", header, got, tt.encoded)
+			}
+			for _, r := range header {
+				if r > 127 {
+					t.Fatalf("Subject header %q is not ASCII", header)
+				}
+			}
+			decoded, err := new(mime.WordDecoder).DecodeHeader(header)
+			if err != nil || decoded != tt.subject {
+				t.Fatalf("decoded Subject = %q, %v; want %q", decoded, err, tt.subject)
+			}
+		})
+	}
+}
+
+func TestMessageBuildAlternative(t *testing.T) {
+	msg := email.Message{
+		To:      "ada@example.com",
+		Subject: "Status changed",
+		Text:    "Your feedback is now planned.\n" + strings.Repeat("long line ", 20),
+		HTML:    "<p>Your feedback is now <b>planned</b>.</p>",
+	}
+	raw, err := msg.Build(testFrom, testDate)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("parse built message: %v", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" || params["boundary"] == "" {
+		t.Fatalf("Content-Type = %q, want multipart/alternative with a boundary", parsed.Header.Get("Content-Type"))
+	}
+	if strings.Contains(msg.Text+msg.HTML, params["boundary"]) {
+		t.Fatal("boundary occurs in a body")
+	}
+
+	parts := multipart.NewReader(parsed.Body, params["boundary"])
+	want := []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", strings.ReplaceAll(msg.Text, "\n", "\r\n")},
+		{"text/html; charset=utf-8", msg.HTML},
+	}
+	for i, w := range want {
+		part, err := parts.NextPart()
+		if err != nil {
+			t.Fatalf("part %d: %v", i, err)
+		}
+		if got := part.Header.Get("Content-Type"); got != w.contentType {
+			t.Errorf("part %d Content-Type = %q, want %q", i, got, w.contentType)
+		}
+		// NextPart decodes quoted-printable and drops the header.
+		body, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("read part %d: %v", i, err)
+		}
+		if string(body) != w.body {
+			t.Errorf("part %d body = %q, want %q", i, body, w.body)
+		}
+	}
+	if _, err := parts.NextPart(); err != io.EOF {
+		t.Fatalf("after two parts: %v, want io.EOF", err)
+	}
+
+	// Quoted-printable keeps body lines within 76 characters.
+	_, body, _ := bytes.Cut(raw, []byte("\r\n\r\n"))
+	for _, line := range strings.Split(string(body), "\r\n") {
+		if len(line) > 76 {
+			t.Fatalf("body line longer than 76 characters: %q", line)
+		}
+	}
+}
+
+func TestMessageBuildStripsHeaderInjection(t *testing.T) {
+	msg := email.Message{
+		To:      "ada@example.com",
+		Subject: "Grüße\r\nBcc: victim@example.com",
+		Text:    "Hi",
+		Headers: map[string]string{"X-Feedback-Id": "42\r\nBcc: other@example.com\n"},
+	}
+	raw, err := msg.Build(testFrom, testDate)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	for _, name := range headerNames(t, raw) {
+		if strings.EqualFold(name, "Bcc") {
+			t.Fatalf("injected Bcc header in:\n%s", raw)
+		}
+	}
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("parse built message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != "GrüßeBcc: victim@example.com" {
+		t.Errorf("Subject = %q, %v", subject, err)
+	}
+	if got := parsed.Header.Get("X-Feedback-Id"); got != "42Bcc: other@example.com" {
+		t.Errorf("X-Feedback-Id = %q", got)
+	}
+}
+
+func TestMessageBuildRejects(t *testing.T) {
+	valid := email.Message{To: "ada@example.com", Subject: "Hi", Text: "Hi"}
+	with := func(change func(*email.Message)) email.Message {
+		msg := valid
+		change(&msg)
+		return msg
+	}
+
+	tests := []struct {
+		name string
+		msg  email.Message
+		from string
+	}{
+		{"reserved header", with(func(m *email.Message) { m.Headers = map[string]string{"From": "evil@example.com"} }), testFrom},
+		{"reserved header in other case", with(func(m *email.Message) { m.Headers = map[string]string{"content-type": "text/html"} }), testFrom},
+		{"message id", with(func(m *email.Message) { m.Headers = map[string]string{"Message-ID": "<x@example.com>"} }), testFrom},
+		{"header name with colon", with(func(m *email.Message) { m.Headers = map[string]string{"X-A: b": "c"} }), testFrom},
+		{"header name with newline", with(func(m *email.Message) { m.Headers = map[string]string{"X-A\r\nBcc": "c"} }), testFrom},
+		{"duplicate header", with(func(m *email.Message) { m.Headers = map[string]string{"X-Tag": "a", "x-tag": "b"} }), testFrom},
+		{"invalid recipient", with(func(m *email.Message) { m.To = "not an address" }), testFrom},
+		{"invalid sender", valid, "nobody"},
+		{"no body", with(func(m *email.Message) { m.Text = "" }), testFrom},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.msg.Build(tt.from, testDate); err == nil {
+				t.Fatal("Build succeeded")
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package email
+
+import (
+	"feedback-app/config"
+	"fmt"
+	"os"
+	"time"
+)
+
+// NewClient builds the email client selected by EMAIL_PROVIDER.
+func NewClient(cfg config.EmailConfig, smtpCfg config.SMTPConfig) (Client, error) {
+	from := smtpCfg.From
+
+	switch cfg.Provider {
+	case config.EmailProviderSMTP:
+		return NewSMTPClient(smtpCfg)
+	case config.EmailProviderHTTP:
+		return NewHTTPClient(cfg.APIURL, cfg.APIKey, from, time.Duration(cfg.APITimeoutSeconds)*time.Second), nil
+	case config.EmailProviderFile:
+		return NewFileSink(cfg.FileDir, from)
+	case config.EmailProviderConsole:
+		return NewConsoleSink(os.Stdout, from), nil
+	case config.EmailProviderCapture:
+		return NewCaptureSink(from), nil
+	default:
+		return nil, fmt.Errorf("unsupported email provider %q", cfg.Provider)
+	}
+}
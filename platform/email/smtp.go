@@ -0,0 +1,246 @@
+package email
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"feedback-app/config"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"sync"
+	"time"
+)
+
+// SMTPClient delivers mail over a small pool of reused SMTP connections, so a
+// burst of login emails does not pay for a TCP and TLS handshake per message.
+// At most PoolSize connections are open at once; further senders wait.
+type SMTPClient struct {
+	cfg         config.SMTPConfig
+	addr        string
+	tlsConfig   *tls.Config
+	dialTimeout time.Duration
+	sendTimeout time.Duration
+	idleTimeout time.Duration
+
+	slots chan struct{}
+	mu    sync.Mutex
+	idle  []*smtpConn
+}
+
+type smtpConn struct {
+	conn     net.Conn
+	client   *smtp.Client
+	lastUsed time.Time
+}
+
+func NewSMTPClient(cfg config.SMTPConfig) (*SMTPClient, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         cfg.Host,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read SMTP CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	poolSize := cfg.PoolSize
+	if poolSize <= 0 {
+		poolSize = 1
+	}
+
+	return &SMTPClient{
+		cfg:         cfg,
+		addr:        net.JoinHostPort(cfg.Host, cfg.Port),
+		tlsConfig:   tlsConfig,
+		dialTimeout: time.Duration(cfg.DialTimeoutSeconds) * time.Second,
+		sendTimeout: time.Duration(cfg.SendTimeoutSeconds) * time.Second,
+		idleTimeout: time.Duration(cfg.IdleTimeoutSeconds) * time.Second,
+		slots:       make(chan struct{}, poolSize),
+	}, nil
+}
+
+func (c *SMTPClient) Send(msg Message) error {
+	body, err := msg.Build(c.cfg.From, time.Now())
+	if err != nil {
+		return err
+	}
+
+	// The envelope needs bare addresses; Build has already validated both.
+	sender, _ := mail.ParseAddress(c.cfg.From)
+	recipient, _ := mail.ParseAddress(msg.To)
+
+	c.slots <- struct{}{}
+	defer func() { <-c.slots }()
+
+	sc, err := c.acquire()
+	if err != nil {
+		return err
+	}
+
+	if err := c.deliver(sc, sender.Address, recipient.Address, body); err != nil {
+		sc.close()
+		return err
+	}
+
+	// RSET leaves the connection ready for the next message. The server has
+	// already accepted this one, so a failure only costs the connection and
+	// must not make the caller send it again.
+	if err := sc.client.Reset(); err != nil {
+		sc.close()
+		return nil
+	}
+
+	c.release(sc)
+	return nil
+}
+
+func (c *SMTPClient) deliver(sc *smtpConn, from string, to string, body []byte) error {
+	if err := sc.conn.SetDeadline(time.Now().Add(c.sendTimeout)); err != nil {
+		return err
+	}
+	if err := sc.client.Mail(from); err != nil {
+		return smtpError(err)
+	}
+	if err := sc.client.Rcpt(to); err != nil {
+		return smtpError(err)
+	}
+	w, err := sc.client.Data()
+	if err != nil {
+		return smtpError(err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	return smtpError(w.Close())
+}
+
+// smtpError marks 5xx replies, such as an unknown recipient or a rejected
+// sender, as permanent. 4xx replies are temporary by definition.
+func smtpError(err error) error {
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return &PermanentError{Err: err}
+	}
+	return err
+}
+
+// acquire returns an idle connection that still answers NOOP, or dials a new
+// one. Connections idle for longer than the idle timeout are closed, since
+// most servers drop them anyway; a zero idle timeout keeps them until the
+// server does.
+func (c *SMTPClient) acquire() (*smtpConn, error) {
+	for {
+		c.mu.Lock()
+		if len(c.idle) == 0 {
+			c.mu.Unlock()
+			return c.dial()
+		}
+		sc := c.idle[len(c.idle)-1]
+		c.idle = c.idle[:len(c.idle)-1]
+		c.mu.Unlock()
+
+		if c.idleTimeout > 0 && time.Since(sc.lastUsed) > c.idleTimeout {
+			sc.close()
+			continue
+		}
+		if err := sc.conn.SetDeadline(time.Now().Add(c.dialTimeout)); err == nil && sc.client.Noop() == nil {
+			return sc, nil
+		}
+		sc.close()
+	}
+}
+
+func (c *SMTPClient) release(sc *smtpConn) {
+	sc.lastUsed = time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.idle = append(c.idle, sc)
+}
+
+func (c *SMTPClient) dial() (*smtpConn, error) {
+	dialer := &net.Dialer{Timeout: c.dialTimeout}
+
+	var conn net.Conn
+	var err error
+	if c.cfg.TLSMode == config.SMTPTLSImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", c.addr, c.tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", c.addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dial SMTP server %s: %w", c.addr, err)
+	}
+
+	// The handshake, STARTTLS and AUTH all have to finish within the dial
+	// timeout.
+	if err := conn.SetDeadline(time.Now().Add(c.dialTimeout)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	client, err := smtp.NewClient(conn, c.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	sc := &smtpConn{conn: conn, client: client}
+
+	if err := c.handshake(client); err != nil {
+		sc.close()
+		return nil, err
+	}
+
+	return sc, nil
+}
+
+func (c *SMTPClient) handshake(client *smtp.Client) error {
+	if c.cfg.TLSMode == config.SMTPTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("SMTP server does not support STARTTLS")
+		}
+		if err := client.StartTLS(c.tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS: %w", err)
+		}
+	}
+
+	if c.cfg.User == "" {
+		return nil
+	}
+	if ok, _ := client.Extension("AUTH"); !ok {
+		return errors.New("SMTP server does not support AUTH")
+	}
+	// PlainAuth refuses to send credentials over an unencrypted connection
+	// to anything but localhost.
+	return client.Auth(smtp.PlainAuth("", c.cfg.User, c.cfg.Password, c.cfg.Host))
+}
+
+// Close closes all idle connections.
+func (c *SMTPClient) Close() error {
+	c.mu.Lock()
+	idle := c.idle
+	c.idle = nil
+	c.mu.Unlock()
+
+	for _, sc := range idle {
+		sc.close()
+	}
+	return nil
+}
+
+func (sc *smtpConn) close() {
+	if err := sc.client.Quit(); err != nil {
+		sc.client.Close()
+	}
+}
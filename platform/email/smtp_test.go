@@ -0,0 +1,396 @@
+package email_test
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"feedback-app/config"
+	"feedback-app/platform/email"
+)
+
+// smtpServer is a minimal in-process ESMTP server. It offers STARTTLS and
+// AUTH PLAIN and can be told to fail the next RCPT, DATA or RSET.
+type smtpServer struct {
+	t        *testing.T
+	ln       net.Listener
+	tls      *tls.Config
+	user     string
+	password string
+
+	mu        sync.Mutex
+	conns     int
+	messages  []string
+	rcptReply string
+	failData  bool
+	failReset bool
+}
+
+func newSMTPServer(t *testing.T, tlsConfig *tls.Config, user, password string) *smtpServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &smtpServer{t: t, ln: ln, tls: tlsConfig, user: user, password: password}
+	t.Cleanup(func() { ln.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *smtpServer) port() string {
+	_, port, _ := net.SplitHostPort(s.ln.Addr().String())
+	return port
+}
+
+func (s *smtpServer) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns++
+		s.mu.Unlock()
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpServer) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(lines ...string) {
+		conn.Write([]byte(strings.Join(lines, "\r\n") + "\r\n"))
+	}
+	secure, authed := false, false
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			if s.tls != nil && !secure {
+				reply("250-localhost", "250-STARTTLS", "250 AUTH PLAIN")
+			} else {
+				reply("250-localhost", "250 AUTH PLAIN")
+			}
+		case "STARTTLS":
+			reply("220 ready to start TLS")
+			tlsConn := tls.Server(conn, s.tls)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, r, secure = tlsConn, bufio.NewReader(tlsConn), true
+		case "AUTH":
+			mechanism, initial, _ := strings.Cut(arg, " ")
+			decoded, err := base64.StdEncoding.DecodeString(initial)
+			parts := strings.Split(string(decoded), "\x00")
+			if mechanism != "PLAIN" || err != nil || len(parts) != 3 ||
+				parts[1] != s.user || parts[2] != s.password || (s.tls != nil && !secure) {
+				reply("535 authentication failed")
+				continue
+			}
+			authed = true
+			reply("235 authenticated")
+		case "MAIL":
+			if s.user != "" && !authed {
+				reply("530 authentication required")
+				continue
+			}
+			reply("250 OK")
+		case "RCPT":
+			s.mu.Lock()
+			rcptReply := s.rcptReply
+			s.rcptReply = ""
+			s.mu.Unlock()
+			if rcptReply != "" {
+				reply(rcptReply)
+				continue
+			}
+			reply("250 OK")
+		case "NOOP":
+			reply("250 OK")
+		case "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			s.mu.Lock()
+			fail := s.failData
+			s.failData = false
+			s.mu.Unlock()
+			if fail {
+				// Hang up halfway through the message.
+				r.ReadString('\n')
+				return
+			}
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, data.String())
+			s.mu.Unlock()
+			reply("250 OK queued")
+		case "RSET":
+			s.mu.Lock()
+			fail := s.failReset
+			s.failReset = false
+			s.mu.Unlock()
+			if fail {
+				reply("421 closing connection")
+				return
+			}
+			reply("250 OK")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 command not implemented")
+		}
+	}
+}
+
+func (s *smtpServer) stats() (conns int, messages []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conns, append([]string(nil), s.messages...)
+}
+
+// selfSignedTLS returns a server config for 127.0.0.1 and the path of a PEM
+// file clients can use as their CA.
+func selfSignedTLS(t *testing.T) (*tls.Config, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write CA file: %v", err)
+	}
+	cert := tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	return &tls.Config{Certificates: []tls.Certificate{cert}}, caFile
+}
+
+func newSMTPClient(t *testing.T, cfg config.SMTPConfig) *email.SMTPClient {
+	t.Helper()
+	cfg.Host = "127.0.0.1"
+	cfg.From = "Feedback <noreply@example.com>"
+	cfg.DialTimeoutSeconds = 5
+	cfg.SendTimeoutSeconds = 5
+	cfg.IdleTimeoutSeconds = 60
+	cfg.PoolSize = 1
+	client, err := email.NewSMTPClient(cfg)
+	if err != nil {
+		t.Fatalf("NewSMTPClient: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func testMessage(subject string) email.Message {
+	return email.Message{To: "user@example.com", Subject: subject, Text: "Hello"}
+}
+
+func TestSMTPClientStartTLSAndAuth(t *testing.T) {
+	serverTLS, caFile := selfSignedTLS(t)
+	server := newSMTPServer(t, serverTLS, "mailer", "secret")
+
+	client := newSMTPClient(t, config.SMTPConfig{
+		Port:     server.port(),
+		User:     "mailer",
+		Password: "secret",
+		TLSMode:  config.SMTPTLSStartTLS,
+		CAFile:   caFile,
+	})
+	if err := client.Send(testMessage("Your login link")); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	_, messages := server.stats()
+	if len(messages) != 1 || !strings.Contains(messages[0], "Subject: Your login link") {
+		t.Fatalf("messages = %q, want one with the subject", messages)
+	}
+
+	wrong := newSMTPClient(t, config.SMTPConfig{
+		Port:     server.port(),
+		User:     "mailer",
+		Password: "wrong",
+		TLSMode:  config.SMTPTLSStartTLS,
+		CAFile:   caFile,
+	})
+	if err := wrong.Send(testMessage("Rejected")); err == nil {
+		t.Fatal("Send with a wrong password succeeded")
+	}
+}
+
+func TestSMTPClientStartTLSRequired(t *testing.T) {
+	server := newSMTPServer(t, nil, "", "")
+
+	client := newSMTPClient(t, config.SMTPConfig{Port: server.port(), TLSMode: config.SMTPTLSStartTLS})
+	if err := client.Send(testMessage("Plaintext")); err == nil {
+		t.Fatal("Send succeeded against a server without STARTTLS")
+	}
+	if _, messages := server.stats(); len(messages) != 0 {
+		t.Fatalf("messages = %q, want none", messages)
+	}
+}
+
+func TestSMTPClientReusesConnection(t *testing.T) {
+	server := newSMTPServer(t, nil, "", "")
+	client := newSMTPClient(t, config.SMTPConfig{Port: server.port()})
+
+	for _, subject := range []string{"First", "Second", "Third"} {
+		if err := client.Send(testMessage(subject)); err != nil {
+			t.Fatalf("Send %s: %v", subject, err)
+		}
+	}
+	conns, messages := server.stats()
+	if conns != 1 {
+		t.Fatalf("connections = %d, want 1", conns)
+	}
+	if len(messages) != 3 {
+		t.Fatalf("messages = %d, want 3", len(messages))
+	}
+}
+
+func TestSMTPClientFailureMidData(t *testing.T) {
+	server := newSMTPServer(t, nil, "", "")
+	client := newSMTPClient(t, config.SMTPConfig{Port: server.port()})
+
+	server.mu.Lock()
+	server.failData = true
+	server.mu.Unlock()
+	if err := client.Send(testMessage("Lost")); err == nil {
+		t.Fatal("Send succeeded although the server hung up during DATA")
+	}
+
+	if err := client.Send(testMessage("Retried")); err != nil {
+		t.Fatalf("Send after failure: %v", err)
+	}
+	conns, messages := server.stats()
+	if conns != 2 {
+		t.Fatalf("connections = %d, want 2 (broken connection must not be reused)", conns)
+	}
+	if len(messages) != 1 || !strings.Contains(messages[0], "Subject: Retried") {
+		t.Fatalf("messages = %q, want only the retried one", messages)
+	}
+}
+
+func TestSMTPClientResetFailureAfterDelivery(t *testing.T) {
+	server := newSMTPServer(t, nil, "", "")
+	client := newSMTPClient(t, config.SMTPConfig{Port: server.port()})
+
+	server.mu.Lock()
+	server.failReset = true
+	server.mu.Unlock()
+	if err := client.Send(testMessage("Delivered")); err != nil {
+		t.Fatalf("Send = %v, want nil once the server accepted the message", err)
+	}
+
+	if err := client.Send(testMessage("Next")); err != nil {
+		t.Fatalf("Send after failed RSET: %v", err)
+	}
+	conns, messages := server.stats()
+	if conns != 2 {
+		t.Fatalf("connections = %d, want 2 (connection must be dropped after failed RSET)", conns)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("messages = %d, want 2", len(messages))
+	}
+}
+
+func TestSMTPClientRejectedRecipient(t *testing.T) {
+	tests := []struct {
+		reply     string
+		permanent bool
+	}{
+		{"550 5.1.1 no such user", true},
+		{"553 mailbox name not allowed", true},
+		{"450 4.2.1 mailbox busy", false},
+		{"452 too many recipients", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reply, func(t *testing.T) {
+			server := newSMTPServer(t, nil, "", "")
+			client := newSMTPClient(t, config.SMTPConfig{Port: server.port()})
+
+			server.mu.Lock()
+			server.rcptReply = tt.reply
+			server.mu.Unlock()
+			err := client.Send(testMessage("Rejected"))
+			if err == nil {
+				t.Fatal("Send succeeded although the recipient was rejected")
+			}
+			if email.IsPermanent(err) != tt.permanent {
+				t.Fatalf("IsPermanent(%v) = %v, want %v", err, !tt.permanent, tt.permanent)
+			}
+		})
+	}
+}
+
+func TestSMTPClientZeroIdleTimeoutKeepsConnections(t *testing.T) {
+	server := newSMTPServer(t, nil, "", "")
+	client, err := email.NewSMTPClient(config.SMTPConfig{
+		Host:               "127.0.0.1",
+		Port:               server.port(),
+		From:               "noreply@example.com",
+		DialTimeoutSeconds: 5,
+		SendTimeoutSeconds: 5,
+		PoolSize:           1,
+	})
+	if err != nil {
+		t.Fatalf("NewSMTPClient: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	for _, subject := range []string{"First", "Second"} {
+		if err := client.Send(testMessage(subject)); err != nil {
+			t.Fatalf("Send %s: %v", subject, err)
+		}
+	}
+	if conns, _ := server.stats(); conns != 1 {
+		t.Fatalf("connections = %d, want 1", conns)
+	}
+}
@@ -0,0 +1,214 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"feedback-app/i18n"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"log"
+	"path"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+)
+
+// TemplateRegistry parses the email templates once and renders them by name
+// and locale. The template tree looks like
+//
+//	layout.html, layout.txt   shared layouts; they render the "content" block
+//	partials/*.html           shared HTML partials such as "button"
+//	<locale>/<name>.html      per-locale content for the HTML part
+//	<locale>/<name>.txt       per-locale content for the plain-text part
+//
+// Missing translations fall back to the default locale. Every template must
+// exist in the default locale with both parts; NewTemplateRegistry refuses
+// to start otherwise.
+type TemplateRegistry struct {
+	fsys fs.FS
+
+	mu   sync.RWMutex
+	html map[string]*htmltemplate.Template
+	text map[string]*texttemplate.Template
+}
+
+// Rendered holds both bodies of a rendered email.
+type Rendered struct {
+	HTML string
+	Text string
+}
+
+func NewTemplateRegistry(fsys fs.FS) (*TemplateRegistry, error) {
+	r := &TemplateRegistry{fsys: fsys}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Render executes template name for locale with data.
+func (r *TemplateRegistry) Render(name string, locale string, data interface{}) (Rendered, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, candidate := range i18n.Fallbacks(locale) {
+		key := path.Join(candidate, name)
+		htmlTmpl, ok := r.html[key]
+		if !ok {
+			continue
+		}
+		textTmpl := r.text[key]
+
+		var htmlBody, textBody bytes.Buffer
+		if err := htmlTmpl.ExecuteTemplate(&htmlBody, "layout.html", data); err != nil {
+			return Rendered{}, err
+		}
+		if err := textTmpl.ExecuteTemplate(&textBody, "layout.txt", data); err != nil {
+			return Rendered{}, err
+		}
+		return Rendered{HTML: htmlBody.String(), Text: textBody.String()}, nil
+	}
+	return Rendered{}, fmt.Errorf("email template %q not found", name)
+}
+
+// Reload parses every template again. On error the previously loaded
+// templates stay in use.
+func (r *TemplateRegistry) Reload() error {
+	htmlBase, err := htmltemplate.New("layout.html").Funcs(templateFuncs(i18n.Default)).ParseFS(r.fsys, "layout.html")
+	if err != nil {
+		return err
+	}
+	if partials, _ := fs.Glob(r.fsys, "partials/*.html"); len(partials) > 0 {
+		if htmlBase, err = htmlBase.ParseFS(r.fsys, partials...); err != nil {
+			return err
+		}
+	}
+	textBase, err := texttemplate.New("layout.txt").ParseFS(r.fsys, "layout.txt")
+	if err != nil {
+		return err
+	}
+
+	html := map[string]*htmltemplate.Template{}
+	text := map[string]*texttemplate.Template{}
+	for _, locale := range i18n.Supported {
+		files, err := fs.Glob(r.fsys, locale+"/*.html")
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			key := strings.TrimSuffix(file, ".html")
+
+			htmlTmpl, err := htmlBase.Clone()
+			if err != nil {
+				return err
+			}
+			if _, err := htmlTmpl.Funcs(templateFuncs(locale)).ParseFS(r.fsys, file); err != nil {
+				return err
+			}
+
+			if _, err := fs.Stat(r.fsys, key+".txt"); err != nil {
+				return fmt.Errorf("%s has no plain-text part: %w", file, err)
+			}
+			textTmpl, err := textBase.Clone()
+			if err != nil {
+				return err
+			}
+			if _, err := textTmpl.ParseFS(r.fsys, key+".txt"); err != nil {
+				return err
+			}
+
+			html[key] = htmlTmpl
+			text[key] = textTmpl
+		}
+	}
+
+	for key := range html {
+		name := path.Base(key)
+		if _, ok := html[path.Join(i18n.Default, name)]; !ok {
+			return fmt.Errorf("email template %s has no %s version to fall back to", key, i18n.Default)
+		}
+	}
+
+	r.mu.Lock()
+	r.html, r.text = html, text
+	r.mu.Unlock()
+	return nil
+}
+
+// Watch polls the template files every interval and reloads them when one
+// changes, until ctx is cancelled. It is meant for development, where fsys is
+// the templates directory on disk.
+func (r *TemplateRegistry) Watch(ctx context.Context, interval time.Duration) {
+	last, err := r.fingerprint()
+	if err != nil {
+		log.Printf("Email template watcher disabled: %v", err)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current, err := r.fingerprint()
+		if err != nil || current == last {
+			continue
+		}
+		last = current
+
+		if err := r.Reload(); err != nil {
+			log.Printf("Failed to reload email templates, keeping the previous ones: %v", err)
+			continue
+		}
+		log.Println("Reloaded email templates")
+	}
+}
+
+// fingerprint summarises the names, sizes and modification times of every
+// file so that any edit, addition or removal changes it.
+func (r *TemplateRegistry) fingerprint() (string, error) {
+	var b strings.Builder
+	err := fs.WalkDir(r.fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "%s:%d:%d;", p, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	return b.String(), err
+}
+
+func templateFuncs(locale string) htmltemplate.FuncMap {
+	return htmltemplate.FuncMap{
+		"locale": func() string { return locale },
+		"dict":   dict,
+	}
+}
+
+// dict builds a map from alternating keys and values so partials can take
+// several arguments: {{template "button" dict "URL" .Link "Label" "Login"}}.
+func dict(pairs ...interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, errors.New("dict needs an even number of arguments")
+	}
+	m := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict key %v is not a string", pairs[i])
+		}
+		m[key] = pairs[i+1]
+	}
+	return m, nil
+}
@@ -0,0 +1,20 @@
+package push
+
+import "log"
+
+// Client delivers push notifications to a user's devices.
+type Client interface {
+	Notify(userID uint, title string, body string) error
+}
+
+type MockClient struct {
+}
+
+func NewMockClient() *MockClient {
+	return &MockClient{}
+}
+
+func (m *MockClient) Notify(userID uint, title string, body string) error {
+	log.Printf("[Mock Push] Notifying user %d: %s - %s", userID, title, body)
+	return nil
+}
@@ -0,0 +1,256 @@
+package repository
+
+import (
+	"errors"
+	"feedback-app/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Board post orderings.
+const (
+	PostSortVotes    = "votes"
+	PostSortTrending = "trending"
+	PostSortNewest   = "newest"
+)
+
+// PostFilter narrows down board listings. Votes only count when the voter's
+// account was created before CountedBefore, so accounts made to stuff the
+// ballot do not move the ranking until they have aged.
+type PostFilter struct {
+	Status        string
+	FeedbackIDs   []uint
+	Sort          string
+	PublishedOnly bool
+	CountedBefore time.Time
+	// TrendingSince is the start of the window whose votes rank trending
+	// posts.
+	TrendingSince time.Time
+	Limit         int
+	Offset        int
+}
+
+const countedVotesSQL = "(SELECT COUNT(*) FROM post_votes JOIN users ON users.id = post_votes.user_id" +
+	" WHERE post_votes.post_id = posts.id AND users.deleted_at IS NULL AND users.created_at <= ?"
+
+type BoardRepository struct {
+	db *gorm.DB
+}
+
+func NewBoardRepository(db *gorm.DB) *BoardRepository {
+	return &BoardRepository{db: db}
+}
+
+// SavePost creates post or updates its title, body and publication time.
+func (r *BoardRepository) SavePost(post *models.Post) error {
+	return r.db.Save(post).Error
+}
+
+func (r *BoardRepository) FindPostByFeedback(feedbackID uint) (*models.Post, error) {
+	var post models.Post
+	if err := r.db.Where("feedback_id = ?", feedbackID).First(&post).Error; err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+// FindPost returns a post with its status, vote and comment counts.
+func (r *BoardRepository) FindPost(id uint, filter PostFilter) (*models.Post, error) {
+	var post models.Post
+	err := r.filterPosts(r.postQuery(r.db, filter), filter).Where("posts.id = ?", id).First(&post).Error
+	if err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+// ListPosts returns a page of posts in the order filter.Sort asks for,
+// together with the total number of matching posts.
+func (r *BoardRepository) ListPosts(filter PostFilter) ([]models.Post, int64, error) {
+	var total int64
+	countQuery := r.filterPosts(readReplica(r.db).Model(&models.Post{}).Joins("JOIN feedbacks ON feedbacks.id = posts.feedback_id"), filter)
+	if err := countQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query := r.filterPosts(r.postQuery(readReplica(r.db), filter), filter)
+	switch filter.Sort {
+	case PostSortNewest:
+		query = query.Order("posts.published_at DESC")
+	case PostSortTrending:
+		query = query.Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:                countedVotesSQL + " AND post_votes.created_at >= ?) DESC, votes DESC, posts.published_at DESC",
+			Vars:               []interface{}{filter.CountedBefore, filter.TrendingSince},
+			WithoutParentheses: true,
+		}})
+	default:
+		query = query.Order("votes DESC").Order("posts.published_at DESC")
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit).Offset(filter.Offset)
+	}
+
+	var posts []models.Post
+	if err := query.Find(&posts).Error; err != nil {
+		return nil, 0, err
+	}
+	return posts, total, nil
+}
+
+func (r *BoardRepository) postQuery(db *gorm.DB, filter PostFilter) *gorm.DB {
+	return db.Model(&models.Post{}).
+		Select("posts.*, feedbacks.status AS status, "+countedVotesSQL+") AS votes, "+
+			"(SELECT COUNT(*) FROM post_comments WHERE post_comments.post_id = posts.id) AS comment_count",
+			filter.CountedBefore).
+		Joins("JOIN feedbacks ON feedbacks.id = posts.feedback_id")
+}
+
+func (r *BoardRepository) filterPosts(query *gorm.DB, filter PostFilter) *gorm.DB {
+	if filter.PublishedOnly {
+		query = query.Where("posts.published_at IS NOT NULL")
+	}
+	if filter.Status != "" {
+		query = query.Where("feedbacks.status = ?", filter.Status)
+	}
+	if filter.FeedbackIDs != nil {
+		query = query.Where("posts.feedback_id IN ?", filter.FeedbackIDs)
+	}
+	return query
+}
+
+// ErrVoteLimit is returned by AddVote when the user has cast too many votes.
+var ErrVoteLimit = errors.New("vote limit reached")
+
+// AddVote records userID's vote on postID and reports whether it is new and
+// whether it is userID's first vote on postID ever. A vote cast again after
+// being withdrawn keeps the time of the first one, so revoting cannot lift a
+// post in the trending sort. Every new vote is logged for CountVotesSince.
+//
+// A new vote is refused with ErrVoteLimit once userID has cast limit votes
+// after since. The user's row is locked while counting, so concurrent votes
+// by the same user cannot all pass the check. Voting again on a post the
+// user currently votes for changes nothing and is never refused.
+func (r *BoardRepository) AddVote(postID uint, userID uint, now time.Time, since time.Time, limit int64) (added bool, first bool, err error) {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		var locked []uint
+		if err := tx.Model(&models.User{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", userID).Pluck("id", &locked).Error; err != nil {
+			return err
+		}
+
+		var existing int64
+		if err := tx.Model(&models.PostVote{}).Where("post_id = ? AND user_id = ?", postID, userID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return nil
+		}
+
+		var recent int64
+		if err := tx.Model(&models.PostVoteEvent{}).Where("user_id = ? AND created_at > ?", userID, since).Count(&recent).Error; err != nil {
+			return err
+		}
+		if recent >= limit {
+			return ErrVoteLimit
+		}
+
+		var earliest models.PostVoteEvent
+		err := tx.Where("post_id = ? AND user_id = ?", postID, userID).Order("created_at").First(&earliest).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		first = errors.Is(err, gorm.ErrRecordNotFound)
+		votedAt := now
+		if !first {
+			votedAt = earliest.CreatedAt
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.PostVote{PostID: postID, UserID: userID, CreatedAt: votedAt})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		added = true
+		return tx.Create(&models.PostVoteEvent{PostID: postID, UserID: userID, CreatedAt: now}).Error
+	})
+	if err != nil {
+		return false, false, err
+	}
+	return added, added && first, nil
+}
+
+func (r *BoardRepository) RemoveVote(postID uint, userID uint) error {
+	return r.db.Where("post_id = ? AND user_id = ?", postID, userID).Delete(&models.PostVote{}).Error
+}
+
+// CountVotesSince returns how many votes userID cast after since, including
+// votes withdrawn since.
+func (r *BoardRepository) CountVotesSince(userID uint, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.PostVoteEvent{}).Where("user_id = ? AND created_at > ?", userID, since).Count(&count).Error
+	return count, err
+}
+
+func (r *BoardRepository) Subscribe(postID uint, userID uint) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.PostSubscription{PostID: postID, UserID: userID}).Error
+}
+
+func (r *BoardRepository) Unsubscribe(postID uint, userID uint) error {
+	return r.db.Where("post_id = ? AND user_id = ?", postID, userID).Delete(&models.PostSubscription{}).Error
+}
+
+// Subscribers returns the users subscribed to postID.
+func (r *BoardRepository) Subscribers(postID uint) ([]models.User, error) {
+	var users []models.User
+	err := r.db.Joins("JOIN post_subscriptions ON post_subscriptions.user_id = users.id").
+		Where("post_subscriptions.post_id = ?", postID).
+		Order("users.id").
+		Find(&users).Error
+	return users, err
+}
+
+// Activity returns the IDs of the posts userID voted on and subscribed to.
+func (r *BoardRepository) Activity(userID uint) ([]uint, []uint, error) {
+	var voted, subscribed []uint
+	if err := r.db.Model(&models.PostVote{}).Where("user_id = ?", userID).Order("post_id").Pluck("post_id", &voted).Error; err != nil {
+		return nil, nil, err
+	}
+	if err := r.db.Model(&models.PostSubscription{}).Where("user_id = ?", userID).Order("post_id").Pluck("post_id", &subscribed).Error; err != nil {
+		return nil, nil, err
+	}
+	return voted, subscribed, nil
+}
+
+func (r *BoardRepository) CreateComment(comment *models.PostComment) error {
+	return r.db.Create(comment).Error
+}
+
+// ListComments returns the comments on postID, oldest first, marking those
+// written by staff.
+func (r *BoardRepository) ListComments(postID uint) ([]models.PostComment, error) {
+	var comments []models.PostComment
+	err := r.db.Model(&models.PostComment{}).
+		Select("post_comments.*, CASE WHEN users.role = ? THEN 1 ELSE 0 END AS staff", models.RoleAdmin).
+		Joins("JOIN users ON users.id = post_comments.author_id").
+		Where("post_comments.post_id = ?", postID).
+		Order("post_comments.created_at, post_comments.id").
+		Find(&comments).Error
+	return comments, err
+}
+
+// RecentComments returns the newest comments across the board with their
+// authors, for moderation.
+func (r *BoardRepository) RecentComments(limit int) ([]models.PostComment, error) {
+	var comments []models.PostComment
+	err := readReplica(r.db).Preload("Author").Order("created_at DESC").Limit(limit).Find(&comments).Error
+	return comments, err
+}
+
+// DeleteComment removes a comment and reports whether it existed.
+func (r *BoardRepository) DeleteComment(id uint) (bool, error) {
+	result := r.db.Delete(&models.PostComment{}, id)
+	return result.RowsAffected > 0, result.Error
+}
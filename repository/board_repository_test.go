@@ -0,0 +1,50 @@
+package repository_test
+
+import (
+	"feedback-app/models"
+	"feedback-app/repository"
+	"testing"
+	"time"
+)
+
+func TestAddVoteAfterUnvote(t *testing.T) {
+	gormDB := newTestDB(t)
+	repo := repository.NewBoardRepository(gormDB)
+	postID, userID := uint(1), uint(1)
+	start := time.Now().Add(-30 * time.Minute)
+
+	added, first, err := repo.AddVote(postID, userID, start, time.Time{}, 10)
+	if err != nil || !added || !first {
+		t.Fatalf("first AddVote = %v, %v, %v; want true, true, nil", added, first, err)
+	}
+	added, first, err = repo.AddVote(postID, userID, start.Add(time.Minute), time.Time{}, 10)
+	if err != nil || added || first {
+		t.Fatalf("repeated AddVote = %v, %v, %v; want false, false, nil", added, first, err)
+	}
+
+	for i := 2; i <= 4; i++ {
+		if err := repo.RemoveVote(postID, userID); err != nil {
+			t.Fatalf("RemoveVote: %v", err)
+		}
+		added, first, err = repo.AddVote(postID, userID, start.Add(time.Duration(i)*time.Minute), time.Time{}, 10)
+		if err != nil || !added || first {
+			t.Fatalf("revote %d = %v, %v, %v; want true, false, nil", i, added, first, err)
+		}
+	}
+
+	count, err := repo.CountVotesSince(userID, start.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("CountVotesSince: %v", err)
+	}
+	if count != 4 {
+		t.Fatalf("CountVotesSince = %d, want 4 (the first vote and three revotes)", count)
+	}
+
+	var vote models.PostVote
+	if err := gormDB.Where("post_id = ? AND user_id = ?", postID, userID).First(&vote).Error; err != nil {
+		t.Fatalf("load vote: %v", err)
+	}
+	if !vote.CreatedAt.Equal(start) {
+		t.Fatalf("vote time = %v, want the first vote's %v", vote.CreatedAt, start)
+	}
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"feedback-app/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ChallengeRepository struct {
+	db *gorm.DB
+}
+
+func NewChallengeRepository(db *gorm.DB) *ChallengeRepository {
+	return &ChallengeRepository{db: db}
+}
+
+// Redeem records tokenHash as used until expiresAt and reports whether it
+// was unused. The unique index decides between concurrent redemptions.
+func (r *ChallengeRepository) Redeem(tokenHash string, expiresAt time.Time, now time.Time) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.ChallengeRedemption{TokenHash: tokenHash, ExpiresAt: expiresAt, CreatedAt: now})
+	return result.RowsAffected == 1, result.Error
+}
+
+// DeleteExpired removes redemptions of challenges that have expired, which
+// cannot be redeemed again anyway.
+func (r *ChallengeRepository) DeleteExpired(now time.Time) error {
+	return r.db.Where("expires_at < ?", now).Delete(&models.ChallengeRedemption{}).Error
+}
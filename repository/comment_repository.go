@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"feedback-app/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CommentRepository struct {
+	db *gorm.DB
+}
+
+func NewCommentRepository(db *gorm.DB) *CommentRepository {
+	return &CommentRepository{db: db}
+}
+
+func (r *CommentRepository) Create(comment *models.Comment) error {
+	return r.db.Create(comment).Error
+}
+
+func (r *CommentRepository) FindByID(id uint) (*models.Comment, error) {
+	var comment models.Comment
+	if err := r.db.First(&comment, id).Error; err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// ListByFeedback returns the conversation on a feedback item, oldest first.
+// Internal notes are only included when includeInternal is set.
+func (r *CommentRepository) ListByFeedback(feedbackID uint, includeInternal bool) ([]models.Comment, error) {
+	query := r.db.Where("feedback_id = ?", feedbackID)
+	if !includeInternal {
+		query = query.Where("visibility = ?", models.CommentVisibilityPublic)
+	}
+
+	var comments []models.Comment
+	err := query.Order("created_at, id").Find(&comments).Error
+	return comments, err
+}
+
+// UpdateBody replaces the body of comment, keeping the previous text as a
+// CommentEdit by editorID.
+func (r *CommentRepository) UpdateBody(comment *models.Comment, editorID uint, body string, now time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		edit := &models.CommentEdit{CommentID: comment.ID, EditorID: editorID, Body: comment.Body, CreatedAt: now}
+		if err := tx.Create(edit).Error; err != nil {
+			return err
+		}
+		err := tx.Model(&models.Comment{}).Where("id = ?", comment.ID).Updates(map[string]interface{}{
+			"body":       body,
+			"edited_at":  now,
+			"updated_at": now,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		comment.Body = body
+		comment.EditedAt = &now
+		comment.UpdatedAt = now
+		return nil
+	})
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"feedback-app/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type EmailRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailRepository(db *gorm.DB) *EmailRepository {
+	return &EmailRepository{db: db}
+}
+
+func (r *EmailRepository) Enqueue(email *models.OutboundEmail) error {
+	return r.db.Create(email).Error
+}
+
+// ClaimDue reserves up to limit queued messages whose next attempt is due,
+// pushing their next attempt out by lease and counting the attempt. Each row
+// is claimed with a conditional UPDATE on its attempt counter, so concurrent
+// workers, also on other servers, never claim the same message twice. A
+// worker that dies mid-send leaves the message to be retried once the lease
+// expires.
+func (r *EmailRepository) ClaimDue(now time.Time, limit int, lease time.Duration) ([]models.OutboundEmail, error) {
+	var candidates []models.OutboundEmail
+	err := r.db.
+		Where("status = ? AND next_attempt_at <= ?", models.EmailStatusQueued, now).
+		Order("next_attempt_at").
+		Limit(limit).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make([]models.OutboundEmail, 0, len(candidates))
+	for _, email := range candidates {
+		result := r.db.Model(&models.OutboundEmail{}).
+			Where("id = ? AND status = ? AND attempts = ?", email.ID, models.EmailStatusQueued, email.Attempts).
+			Updates(map[string]interface{}{
+				"attempts":        email.Attempts + 1,
+				"next_attempt_at": now.Add(lease),
+				"updated_at":      now,
+			})
+		if result.Error != nil {
+			return claimed, result.Error
+		}
+		if result.RowsAffected == 1 {
+			email.Attempts++
+			claimed = append(claimed, email)
+		}
+	}
+	return claimed, nil
+}
+
+// MarkSent records a successful delivery and drops the bodies.
+func (r *EmailRepository) MarkSent(id uint, now time.Time) error {
+	return r.db.Model(&models.OutboundEmail{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":     models.EmailStatusSent,
+		"sent_at":    now,
+		"last_error": "",
+		"text_body":  "",
+		"html_body":  "",
+		"headers":    nil,
+		"updated_at": now,
+	}).Error
+}
+
+// MarkRetry records a failed attempt and schedules the next one.
+func (r *EmailRepository) MarkRetry(id uint, lastError string, nextAttemptAt time.Time) error {
+	return r.db.Model(&models.OutboundEmail{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_error":      lastError,
+		"next_attempt_at": nextAttemptAt,
+		"updated_at":      time.Now(),
+	}).Error
+}
+
+// MarkFailed gives up on a message after its last attempt. Messages with an
+// expiry cannot be requeued, so their bodies, which hold one-time links, are
+// dropped as for sent ones.
+func (r *EmailRepository) MarkFailed(id uint, lastError string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.OutboundEmail{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status":     models.EmailStatusFailed,
+			"last_error": lastError,
+			"updated_at": time.Now(),
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.OutboundEmail{}).Where("id = ? AND expires_at IS NOT NULL", id).Updates(map[string]interface{}{
+			"text_body": "",
+			"html_body": "",
+			"headers":   nil,
+		}).Error
+	})
+}
+
+// Requeue puts a failed message back in the queue with a fresh set of
+// attempts. It returns gorm.ErrRecordNotFound unless the message had failed
+// and has no expiry.
+func (r *EmailRepository) Requeue(id uint, now time.Time) error {
+	result := r.db.Model(&models.OutboundEmail{}).
+		Where("id = ? AND status = ? AND expires_at IS NULL", id, models.EmailStatusFailed).
+		Updates(map[string]interface{}{
+			"status":          models.EmailStatusQueued,
+			"attempts":        0,
+			"next_attempt_at": now,
+			"updated_at":      now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// List returns a page of messages with status, most recently updated first,
+// together with the total number of matching rows.
+func (r *EmailRepository) List(status string, limit int, offset int) ([]models.OutboundEmail, int64, error) {
+	query := readReplica(r.db).Model(&models.OutboundEmail{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []models.OutboundEmail
+	err := query.Omit("text_body", "html_body", "headers").
+		Order("updated_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&items).Error
+	return items, total, err
+}
+
+func (r *EmailRepository) CountByStatus() (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := readReplica(r.db).Model(&models.OutboundEmail{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,134 @@
+package repository_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"feedback-app/models"
+	"feedback-app/repository"
+
+	"gorm.io/gorm"
+)
+
+func enqueueEmail(t *testing.T, repo *repository.EmailRepository, subject string, now time.Time) *models.OutboundEmail {
+	t.Helper()
+	email := &models.OutboundEmail{
+		Recipient:     "ada@example.com",
+		Subject:       subject,
+		TextBody:      "Hello",
+		HTMLBody:      "<p>Hello</p>",
+		Status:        models.EmailStatusQueued,
+		NextAttemptAt: now,
+	}
+	if err := repo.Enqueue(email); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	return email
+}
+
+func TestClaimDueConcurrent(t *testing.T) {
+	repo := repository.NewEmailRepository(newTestDB(t))
+	now := time.Now()
+
+	const emails, workers = 20, 4
+	for i := 0; i < emails; i++ {
+		enqueueEmail(t, repo, fmt.Sprintf("Message %d", i), now.Add(-time.Minute))
+	}
+	enqueueEmail(t, repo, "Not due", now.Add(time.Hour))
+
+	var mu sync.Mutex
+	claims := make(map[uint]int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				claimed, err := repo.ClaimDue(now, 1, 5*time.Minute)
+				if err != nil {
+					t.Errorf("ClaimDue: %v", err)
+					return
+				}
+				if len(claimed) == 0 {
+					return
+				}
+				mu.Lock()
+				for _, email := range claimed {
+					claims[email.ID]++
+					if email.Attempts != 1 {
+						t.Errorf("email %d claimed with %d attempts, want 1", email.ID, email.Attempts)
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(claims) != emails {
+		t.Fatalf("%d emails claimed, want %d", len(claims), emails)
+	}
+	for id, count := range claims {
+		if count != 1 {
+			t.Errorf("email %d claimed %d times", id, count)
+		}
+	}
+
+	// The lease pushes claimed messages out until it expires.
+	if claimed, err := repo.ClaimDue(now.Add(time.Minute), emails, 5*time.Minute); err != nil || len(claimed) != 0 {
+		t.Fatalf("ClaimDue during the lease = %d, %v; want none", len(claimed), err)
+	}
+	if claimed, err := repo.ClaimDue(now.Add(6*time.Minute), emails, 5*time.Minute); err != nil || len(claimed) != emails {
+		t.Fatalf("ClaimDue after the lease = %d, %v; want %d", len(claimed), err, emails)
+	}
+}
+
+func TestMarkFailedDropsExpiringBodies(t *testing.T) {
+	gormDB := newTestDB(t)
+	repo := repository.NewEmailRepository(gormDB)
+	now := time.Now()
+
+	notification := enqueueEmail(t, repo, "New comment", now)
+	login := enqueueEmail(t, repo, "Login", now)
+	expiresAt := now.Add(15 * time.Minute)
+	if err := gormDB.Model(login).Update("expires_at", expiresAt).Error; err != nil {
+		t.Fatalf("set expiry: %v", err)
+	}
+
+	for _, email := range []*models.OutboundEmail{notification, login} {
+		if err := repo.MarkFailed(email.ID, "550 no such user"); err != nil {
+			t.Fatalf("MarkFailed: %v", err)
+		}
+	}
+
+	load := func(id uint) models.OutboundEmail {
+		t.Helper()
+		var email models.OutboundEmail
+		if err := gormDB.First(&email, id).Error; err != nil {
+			t.Fatalf("load email: %v", err)
+		}
+		return email
+	}
+	if email := load(notification.ID); email.Status != models.EmailStatusFailed || email.TextBody == "" || email.HTMLBody == "" {
+		t.Fatalf("failed notification = %+v, want failed with its bodies kept", email)
+	}
+	if email := load(login.ID); email.Status != models.EmailStatusFailed || email.TextBody != "" || email.HTMLBody != "" {
+		t.Fatalf("failed login email = %+v, want failed without bodies", email)
+	}
+
+	if err := repo.Requeue(notification.ID, now); err != nil {
+		t.Fatalf("Requeue notification: %v", err)
+	}
+	if email := load(notification.ID); email.Status != models.EmailStatusQueued || email.Attempts != 0 {
+		t.Fatalf("requeued notification = %+v, want queued with no attempts", email)
+	}
+	if err := repo.Requeue(login.ID, now); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("Requeue login email = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if err := repo.Requeue(notification.ID, now); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("Requeue queued email = %v, want gorm.ErrRecordNotFound", err)
+	}
+}
@@ -15,15 +15,169 @@ func NewFeedbackRepository(db *gorm.DB) *FeedbackRepository {
 	return &FeedbackRepository{db: db}
 }
 
+// FeedbackFilter narrows down feedback listings. Zero values are ignored.
+type FeedbackFilter struct {
+	Status   string
+	Category string
+	Tag      string
+	Query    string
+	UserID   uint
+	// Moderation limits the list to approved or pending feedback; empty
+	// matches both.
+	Moderation string
+	From       time.Time
+	To         time.Time
+	Limit      int
+	Offset     int
+}
+
 func (r *FeedbackRepository) Create(feedback *models.Feedback) error {
 	return r.db.Create(feedback).Error
 }
 
-func (r *FeedbackRepository) CheckDuplicate(userID uint, content string) (bool, error) {
+// CreateBatch inserts many feedback items at once, including their tags.
+func (r *FeedbackRepository) CreateBatch(items []models.Feedback, batchSize int) error {
+	return r.db.CreateInBatches(items, batchSize).Error
+}
+
+// CheckDuplicate reports whether userID sent the same content in the last
+// five minutes. A nil userID checks guest feedback.
+func (r *FeedbackRepository) CheckDuplicate(userID *uint, content string) (bool, error) {
 	var count int64
 	window := time.Now().Add(-5 * time.Minute)
-	err := r.db.Model(&models.Feedback{}).
-		Where("user_id = ? AND content = ? AND created_at > ?", userID, content, window).
-		Count(&count).Error
+	query := r.db.Model(&models.Feedback{}).Where("content = ? AND created_at > ?", content, window)
+	if userID == nil {
+		query = query.Where("user_id IS NULL")
+	} else {
+		query = query.Where("user_id = ?", *userID)
+	}
+	err := query.Count(&count).Error
 	return count > 0, err
 }
+
+func (r *FeedbackRepository) FindByID(id uint) (*models.Feedback, error) {
+	var feedback models.Feedback
+	err := r.db.Preload("User").
+		Preload("Site").
+		Preload("Tags").
+		Preload("Comments", func(db *gorm.DB) *gorm.DB { return db.Order("created_at, id") }).
+		Preload("Comments.Author").
+		Preload("Comments.Edits", func(db *gorm.DB) *gorm.DB { return db.Order("created_at, id") }).
+		Preload("Comments.Edits.Editor").
+		First(&feedback, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &feedback, nil
+}
+
+// List returns a page of feedback matching filter, newest first, together
+// with the total number of matching rows.
+func (r *FeedbackRepository) List(filter FeedbackFilter) ([]models.Feedback, int64, error) {
+	query := r.applyFilter(readReplica(r.db).Model(&models.Feedback{}), filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []models.Feedback
+	query = query.Preload("User").Preload("Site").Preload("Tags").Order("feedbacks.created_at DESC")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit).Offset(filter.Offset)
+	}
+	if err := query.Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return items, total, nil
+}
+
+func (r *FeedbackRepository) applyFilter(query *gorm.DB, filter FeedbackFilter) *gorm.DB {
+	if filter.Status != "" {
+		query = query.Where("feedbacks.status = ?", filter.Status)
+	}
+	if filter.Category != "" {
+		query = query.Where("feedbacks.category = ?", filter.Category)
+	}
+	if filter.UserID != 0 {
+		query = query.Where("feedbacks.user_id = ?", filter.UserID)
+	}
+	if filter.Moderation != "" {
+		query = query.Where("feedbacks.moderation = ?", filter.Moderation)
+	}
+	if filter.Query != "" {
+		query = query.Where("feedbacks.content LIKE ? ESCAPE '!'", "%"+escapeLike(filter.Query)+"%")
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("feedbacks.created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("feedbacks.created_at < ?", filter.To)
+	}
+	if filter.Tag != "" {
+		query = query.Where(
+			"EXISTS (SELECT 1 FROM feedback_tags JOIN tags ON tags.id = feedback_tags.tag_id WHERE feedback_tags.feedback_id = feedbacks.id AND tags.name = ?)",
+			filter.Tag,
+		)
+	}
+	return query
+}
+
+func (r *FeedbackRepository) UpdateModeration(id uint, moderation string) error {
+	return r.db.Model(&models.Feedback{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"moderation": moderation,
+		"updated_at": time.Now(),
+	}).Error
+}
+
+// Delete removes a feedback item; its comments, tags and board post go with
+// it through the foreign keys.
+func (r *FeedbackRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Feedback{}, id).Error
+}
+
+func (r *FeedbackRepository) UpdateStatus(id uint, status string) error {
+	return r.db.Model(&models.Feedback{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":     status,
+		"updated_at": time.Now(),
+	}).Error
+}
+
+// ReplaceTags swaps the tag set of a feedback item for tags.
+func (r *FeedbackRepository) ReplaceTags(feedback *models.Feedback, tags []models.Tag) error {
+	return r.db.Model(feedback).Association("Tags").Replace(tags)
+}
+
+// CreatedTimesSince returns the creation time of every approved feedback
+// item created after since. Bucketing happens in Go to keep the query
+// portable.
+func (r *FeedbackRepository) CreatedTimesSince(since time.Time) ([]time.Time, error) {
+	var times []time.Time
+	err := readReplica(r.db).Model(&models.Feedback{}).
+		Where("created_at >= ? AND moderation = ?", since, models.ModerationApproved).
+		Order("created_at").
+		Pluck("created_at", &times).Error
+	return times, err
+}
+
+func (r *FeedbackRepository) CountByStatus() (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := readReplica(r.db).Model(&models.Feedback{}).
+		Select("status, COUNT(*) AS count").
+		Where("moderation = ?", models.ModerationApproved).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,129 @@
+package repository_test
+
+import (
+	"feedback-app/models"
+	"feedback-app/repository"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestCheckDuplicate(t *testing.T) {
+	repo := repository.NewFeedbackRepository(newTestDB(t))
+	userID, otherID := uint(1), uint(2)
+
+	recent := &models.Feedback{UserID: &userID, Content: "The export button is broken", Category: models.FeedbackCategoryBug, CreatedAt: time.Now().Add(-time.Minute)}
+	if err := repo.Create(recent); err != nil {
+		t.Fatalf("create feedback: %v", err)
+	}
+	old := &models.Feedback{UserID: &userID, Content: "Dark mode please", Category: models.FeedbackCategoryFeatureRequest, CreatedAt: time.Now().Add(-6 * time.Minute)}
+	if err := repo.Create(old); err != nil {
+		t.Fatalf("create feedback: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		userID  *uint
+		content string
+		want    bool
+	}{
+		{"same user within window", &userID, "The export button is broken", true},
+		{"same user after window", &userID, "Dark mode please", false},
+		{"other content", &userID, "Something else", false},
+		{"other user", &otherID, "The export button is broken", false},
+		{"guest", nil, "The export button is broken", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.CheckDuplicate(tt.userID, tt.content)
+			if err != nil {
+				t.Fatalf("CheckDuplicate: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckDuplicateGuest(t *testing.T) {
+	repo := repository.NewFeedbackRepository(newTestDB(t))
+	userID := uint(1)
+
+	guest := &models.Feedback{Content: "Love the new editor", Category: models.FeedbackCategoryPraise, Moderation: models.ModerationPending, CreatedAt: time.Now()}
+	if err := repo.Create(guest); err != nil {
+		t.Fatalf("create feedback: %v", err)
+	}
+
+	if dup, err := repo.CheckDuplicate(nil, "Love the new editor"); err != nil || !dup {
+		t.Errorf("guest resubmission: got %v, %v; want duplicate", dup, err)
+	}
+	if dup, err := repo.CheckDuplicate(&userID, "Love the new editor"); err != nil || dup {
+		t.Errorf("user with guest's content: got %v, %v; want no duplicate", dup, err)
+	}
+}
+
+func TestSearchEscapesWildcards(t *testing.T) {
+	testSearchEscapesWildcards(t, newTestDB(t))
+}
+
+// testSearchEscapesWildcards also runs in the integration suite, since each
+// database quotes the ESCAPE clause differently.
+func testSearchEscapesWildcards(t *testing.T, gormDB *gorm.DB) {
+	feedbackRepo := repository.NewFeedbackRepository(gormDB)
+	userRepo := repository.NewUserRepository(gormDB)
+
+	for _, content := range []string{"Save 50% on exports", "Save 500 on exports", `Path C:\temp\x is wrong`, "snake_case names", "snakeXcase names", "Wow! Great"} {
+		if err := feedbackRepo.Create(&models.Feedback{Content: content}); err != nil {
+			t.Fatalf("create feedback: %v", err)
+		}
+	}
+	for _, address := range []string{"ann@seed.example.com", "bob@seedxexample.com", "carl_1@example.com", "carl21@example.com"} {
+		if err := userRepo.Create(&models.User{Email: address}); err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  int64
+	}{
+		{"50%", 1},
+		{"%", 1},
+		{"snake_case", 1},
+		{"_", 1},
+		{`C:\temp`, 1},
+		{`\`, 1},
+		{"!", 1},
+		{"on exports", 2},
+	}
+	for _, tt := range tests {
+		_, total, err := feedbackRepo.List(repository.FeedbackFilter{Query: tt.query})
+		if err != nil {
+			t.Fatalf("List(%q): %v", tt.query, err)
+		}
+		if total != tt.want {
+			t.Errorf("List(%q) matched %d, want %d", tt.query, total, tt.want)
+		}
+	}
+
+	users, err := userRepo.SearchByEmail("carl_", 10)
+	if err != nil {
+		t.Fatalf("SearchByEmail: %v", err)
+	}
+	if len(users) != 1 || users[0].Email != "carl_1@example.com" {
+		t.Errorf("SearchByEmail(carl_) = %v, want only carl_1@example.com", users)
+	}
+
+	removed, err := userRepo.DeleteByEmailSuffix("@seed.example.com")
+	if err != nil {
+		t.Fatalf("DeleteByEmailSuffix: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("DeleteByEmailSuffix removed %d users, want 1", removed)
+	}
+	if _, err := userRepo.FindByEmail("bob@seedxexample.com"); err != nil {
+		t.Errorf("user matching the suffix only by wildcard was deleted: %v", err)
+	}
+}
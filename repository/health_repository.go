@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type HealthRepository struct {
+	db *gorm.DB
+}
+
+func NewHealthRepository(db *gorm.DB) *HealthRepository {
+	return &HealthRepository{db: db}
+}
+
+func (r *HealthRepository) Ping(ctx context.Context) error {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// SchemaVersion reads the version recorded by golang-migrate. A database
+// without the schema_migrations table or row reports version 0.
+func (r *HealthRepository) SchemaVersion() (uint, bool, error) {
+	if !r.db.Migrator().HasTable("schema_migrations") {
+		return 0, false, nil
+	}
+
+	var row struct {
+		Version uint
+		Dirty   bool
+	}
+	result := r.db.Table("schema_migrations").Select("version, dirty").Limit(1).Scan(&row)
+	if result.Error != nil {
+		return 0, false, result.Error
+	}
+	return row.Version, row.Dirty, nil
+}
@@ -0,0 +1,16 @@
+package repository
+
+import "strings"
+
+// likeEscape is the escape character of LIKE patterns built by escapeLike.
+// A backslash would need different quoting in MySQL and PostgreSQL.
+const likeEscape = "!"
+
+var likeEscaper = strings.NewReplacer(likeEscape, likeEscape+likeEscape, "%", likeEscape+"%", "_", likeEscape+"_")
+
+// escapeLike makes s match itself literally in a LIKE pattern. Use it with
+// " LIKE ? ESCAPE '!'", so that searching for "50%" or "a_b" does not match
+// everything.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
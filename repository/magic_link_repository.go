@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
 var ErrTokenUsed = errors.New("token already used")
@@ -37,23 +36,31 @@ func (r *MagicLinkRepository) MarkUsed(id uint) error {
 }
 
 // ConsumeByToken marks a token as used in a single transaction to prevent reuse.
+// The conditional UPDATE is atomic on every supported database, so no row
+// lock is needed; when it matches nothing the row is read back to tell a used
+// token from an expired one.
 func (r *MagicLinkRepository) ConsumeByToken(token string, now time.Time) (*models.MagicLink, error) {
 	var link models.MagicLink
 
 	err := r.db.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("token = ?", token).First(&link).Error; err != nil {
+		result := tx.Model(&models.MagicLink{}).
+			Where("token = ? AND used = ? AND expires_at >= ?", token, false, now).
+			Update("used", true)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if err := tx.Where("token = ?", token).First(&link).Error; err != nil {
 			return err
 		}
 
+		if result.RowsAffected == 1 {
+			return nil
+		}
 		if link.Used {
 			return ErrTokenUsed
 		}
-
-		if now.After(link.ExpiresAt) {
-			return ErrTokenExpired
-		}
-
-		return tx.Model(&models.MagicLink{}).Where("id = ?", link.ID).Update("used", true).Error
+		return ErrTokenExpired
 	})
 	if err != nil {
 		return nil, err
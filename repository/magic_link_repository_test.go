@@ -0,0 +1,128 @@
+package repository_test
+
+import (
+	"errors"
+	"feedback-app/db"
+	"feedback-app/models"
+	"feedback-app/repository"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// newTestDB opens a fresh in-memory SQLite database. A plain
+// "file::memory:" database belongs to a single connection, so the pool is
+// limited to one to keep every query on the same schema.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	gormDB, err := db.InitSQLite("file::memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		t.Fatalf("sqlite handle: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	return gormDB
+}
+
+func createMagicLink(t *testing.T, repo *repository.MagicLinkRepository, token string, expiresAt time.Time) {
+	t.Helper()
+
+	link := &models.MagicLink{UserID: 1, Token: token, ExpiresAt: expiresAt, CreatedAt: time.Now()}
+	if err := repo.Create(link); err != nil {
+		t.Fatalf("create magic link: %v", err)
+	}
+}
+
+func TestConsumeByToken(t *testing.T) {
+	repo := repository.NewMagicLinkRepository(newTestDB(t))
+	now := time.Now()
+	createMagicLink(t, repo, "valid", now.Add(15*time.Minute))
+
+	link, err := repo.ConsumeByToken("valid", now)
+	if err != nil {
+		t.Fatalf("ConsumeByToken: %v", err)
+	}
+	if link.UserID != 1 || !link.Used {
+		t.Errorf("got link %+v, want user 1 marked used", link)
+	}
+}
+
+func TestConsumeByTokenReused(t *testing.T) {
+	repo := repository.NewMagicLinkRepository(newTestDB(t))
+	now := time.Now()
+	createMagicLink(t, repo, "reused", now.Add(15*time.Minute))
+
+	if _, err := repo.ConsumeByToken("reused", now); err != nil {
+		t.Fatalf("first ConsumeByToken: %v", err)
+	}
+	if _, err := repo.ConsumeByToken("reused", now); !errors.Is(err, repository.ErrTokenUsed) {
+		t.Errorf("second ConsumeByToken: got %v, want ErrTokenUsed", err)
+	}
+}
+
+func TestConsumeByTokenExpired(t *testing.T) {
+	repo := repository.NewMagicLinkRepository(newTestDB(t))
+	now := time.Now()
+	createMagicLink(t, repo, "expired", now.Add(-time.Minute))
+
+	if _, err := repo.ConsumeByToken("expired", now); !errors.Is(err, repository.ErrTokenExpired) {
+		t.Errorf("got %v, want ErrTokenExpired", err)
+	}
+
+	// A failed attempt must not burn the token.
+	link, err := repo.FindByToken("expired")
+	if err != nil {
+		t.Fatalf("FindByToken: %v", err)
+	}
+	if link.Used {
+		t.Error("expired token was marked used")
+	}
+}
+
+func TestConsumeByTokenUnknown(t *testing.T) {
+	repo := repository.NewMagicLinkRepository(newTestDB(t))
+
+	if _, err := repo.ConsumeByToken("missing", time.Now()); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("got %v, want gorm.ErrRecordNotFound", err)
+	}
+}
+
+func TestConsumeByTokenConcurrent(t *testing.T) {
+	repo := repository.NewMagicLinkRepository(newTestDB(t))
+	now := time.Now()
+	createMagicLink(t, repo, "contended", now.Add(15*time.Minute))
+
+	const attempts = 10
+	errs := make(chan error, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.ConsumeByToken("contended", now)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	consumed := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			consumed++
+		case !errors.Is(err, repository.ErrTokenUsed):
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if consumed != 1 {
+		t.Errorf("token consumed %d times, want exactly once", consumed)
+	}
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"feedback-app/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReleaseRepository struct {
+	db *gorm.DB
+}
+
+func NewReleaseRepository(db *gorm.DB) *ReleaseRepository {
+	return &ReleaseRepository{db: db}
+}
+
+func (r *ReleaseRepository) Create(release *models.Release) error {
+	return r.db.Create(release).Error
+}
+
+// Update saves the version, title and notes of release.
+func (r *ReleaseRepository) Update(release *models.Release) error {
+	return r.db.Model(release).Select("version", "title", "notes", "updated_at").Updates(release).Error
+}
+
+// FindByID returns a release with its linked feedback.
+func (r *ReleaseRepository) FindByID(id uint) (*models.Release, error) {
+	var release models.Release
+	err := r.db.Preload("Feedback", func(db *gorm.DB) *gorm.DB { return db.Order("feedbacks.id") }).
+		First(&release, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+func (r *ReleaseRepository) FindByVersion(version string) (*models.Release, error) {
+	var release models.Release
+	if err := r.db.Where("version = ?", version).First(&release).Error; err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+// List returns a page of releases with their linked feedback, newest first,
+// and the number of matching releases. Drafts come first when they are
+// included.
+func (r *ReleaseRepository) List(publishedOnly bool, limit int, offset int) ([]models.Release, int64, error) {
+	query := readReplica(r.db).Model(&models.Release{})
+	if publishedOnly {
+		query = query.Where("published_at IS NOT NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var releases []models.Release
+	err := query.Preload("Feedback", func(db *gorm.DB) *gorm.DB { return db.Order("feedbacks.id") }).
+		Order("published_at IS NOT NULL").
+		Order("published_at DESC").
+		Order("id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&releases).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return releases, total, nil
+}
+
+// ReplaceFeedback links release to exactly the feedback items feedbackIDs.
+// It returns gorm.ErrRecordNotFound if one of them does not exist.
+func (r *ReleaseRepository) ReplaceFeedback(release *models.Release, feedbackIDs []uint) error {
+	items := []models.Feedback{}
+	if len(feedbackIDs) > 0 {
+		if err := r.db.Where("id IN ?", feedbackIDs).Find(&items).Error; err != nil {
+			return err
+		}
+		if len(items) != len(feedbackIDs) {
+			return gorm.ErrRecordNotFound
+		}
+	}
+	return r.db.Model(release).Association("Feedback").Replace(items)
+}
+
+// Publish marks release as published at now and its linked feedback as
+// done, in one transaction. It reports false without changing anything when
+// the release was already published, so concurrent requests publish it once.
+func (r *ReleaseRepository) Publish(release *models.Release, now time.Time) (bool, error) {
+	published := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Release{}).
+			Where("id = ? AND published_at IS NULL", release.ID).
+			Updates(map[string]interface{}{"published_at": now, "updated_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return nil
+		}
+		published = true
+		release.PublishedAt = &now
+		release.UpdatedAt = now
+		ids := make([]uint, 0, len(release.Feedback))
+		for _, feedback := range release.Feedback {
+			ids = append(ids, feedback.ID)
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		return tx.Model(&models.Feedback{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":     models.FeedbackStatusDone,
+			"updated_at": now,
+		}).Error
+	})
+	return published && err == nil, err
+}
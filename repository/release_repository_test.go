@@ -0,0 +1,59 @@
+package repository_test
+
+import (
+	"feedback-app/models"
+	"feedback-app/repository"
+	"testing"
+	"time"
+)
+
+func TestPublishOnce(t *testing.T) {
+	gormDB := newTestDB(t)
+	repo := repository.NewReleaseRepository(gormDB)
+	feedbackRepo := repository.NewFeedbackRepository(gormDB)
+
+	feedback := &models.Feedback{Content: "Exports time out", Category: models.FeedbackCategoryBug, Status: models.FeedbackStatusPlanned}
+	if err := feedbackRepo.Create(feedback); err != nil {
+		t.Fatalf("create feedback: %v", err)
+	}
+	release := &models.Release{Version: "1.4.0", Title: "Faster exports", Notes: "Exports stream now."}
+	if err := repo.Create(release); err != nil {
+		t.Fatalf("create release: %v", err)
+	}
+	if err := repo.ReplaceFeedback(release, []uint{feedback.ID}); err != nil {
+		t.Fatalf("link feedback: %v", err)
+	}
+
+	// Two requests that both loaded the draft before either published it.
+	first, err := repo.FindByID(release.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	second, err := repo.FindByID(release.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+
+	published, err := repo.Publish(first, time.Now())
+	if err != nil || !published {
+		t.Fatalf("first Publish = %v, %v; want true, nil", published, err)
+	}
+	if first.PublishedAt == nil {
+		t.Fatal("first Publish did not set PublishedAt")
+	}
+	published, err = repo.Publish(second, time.Now().Add(time.Minute))
+	if err != nil || published {
+		t.Fatalf("second Publish = %v, %v; want false, nil", published, err)
+	}
+
+	stored, err := repo.FindByID(release.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if stored.PublishedAt == nil || !stored.PublishedAt.Equal(*first.PublishedAt) {
+		t.Fatalf("PublishedAt = %v, want %v", stored.PublishedAt, first.PublishedAt)
+	}
+	if stored.Feedback[0].Status != models.FeedbackStatusDone {
+		t.Fatalf("feedback status = %q, want done", stored.Feedback[0].Status)
+	}
+}
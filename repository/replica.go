@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// ReplicaResolver names the dbresolver policy that db.InitDB registers when a
+// read replica is configured. Queries opt in with readReplica; everything
+// else, including reads that must see the caller's own writes, uses the
+// primary.
+const ReplicaResolver = "replica"
+
+// readReplica routes the reads of query to the replica when one is
+// configured. Use it only for listings, search and analytics, which tolerate
+// replication lag.
+func readReplica(query *gorm.DB) *gorm.DB {
+	return query.Clauses(dbresolver.Use(ReplicaResolver))
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"feedback-app/models"
+
+	"gorm.io/gorm"
+)
+
+type SiteRepository struct {
+	db *gorm.DB
+}
+
+func NewSiteRepository(db *gorm.DB) *SiteRepository {
+	return &SiteRepository{db: db}
+}
+
+func (r *SiteRepository) Create(site *models.Site) error {
+	return r.db.Create(site).Error
+}
+
+// Update saves the name and allowed origins of site. The public key never
+// changes, since it is pasted into the embedding pages.
+func (r *SiteRepository) Update(site *models.Site) error {
+	return r.db.Model(site).Select("name", "allowed_origins", "updated_at").Updates(site).Error
+}
+
+func (r *SiteRepository) FindByID(id uint) (*models.Site, error) {
+	var site models.Site
+	if err := r.db.First(&site, id).Error; err != nil {
+		return nil, err
+	}
+	return &site, nil
+}
+
+func (r *SiteRepository) FindByPublicKey(key string) (*models.Site, error) {
+	var site models.Site
+	if err := r.db.Where("public_key = ?", key).First(&site).Error; err != nil {
+		return nil, err
+	}
+	return &site, nil
+}
+
+func (r *SiteRepository) All() ([]models.Site, error) {
+	var sites []models.Site
+	err := readReplica(r.db).Order("name, id").Find(&sites).Error
+	return sites, err
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"feedback-app/models"
+	"time"
+)
+
+// The store interfaces describe what the services need from persistence.
+// The GORM repositories in this package implement them for every supported
+// database; services depend only on the interfaces.
+
+type UserStore interface {
+	FindByEmail(email string) (*models.User, error)
+	FindByID(id uint) (*models.User, error)
+	Create(user *models.User) error
+	SearchByEmail(query string, limit int) ([]models.User, error)
+	UpdateRole(id uint, role string) error
+	UpdateLocale(id uint, locale string) error
+	UpdateNotifications(id uint, onReply bool, onStatus bool) error
+	DeleteByEmailSuffix(suffix string) (int64, error)
+}
+
+type MagicLinkStore interface {
+	Create(link *models.MagicLink) error
+	FindByToken(token string) (*models.MagicLink, error)
+	ConsumeByToken(token string, now time.Time) (*models.MagicLink, error)
+	CleanupExpiredTokens() error
+}
+
+type ChallengeStore interface {
+	Redeem(tokenHash string, expiresAt time.Time, now time.Time) (bool, error)
+	DeleteExpired(now time.Time) error
+}
+
+type FeedbackStore interface {
+	Create(feedback *models.Feedback) error
+	CreateBatch(items []models.Feedback, batchSize int) error
+	CheckDuplicate(userID *uint, content string) (bool, error)
+	FindByID(id uint) (*models.Feedback, error)
+	List(filter FeedbackFilter) ([]models.Feedback, int64, error)
+	UpdateStatus(id uint, status string) error
+	UpdateModeration(id uint, moderation string) error
+	Delete(id uint) error
+	ReplaceTags(feedback *models.Feedback, tags []models.Tag) error
+	CreatedTimesSince(since time.Time) ([]time.Time, error)
+	CountByStatus() (map[string]int64, error)
+}
+
+type CommentStore interface {
+	Create(comment *models.Comment) error
+	FindByID(id uint) (*models.Comment, error)
+	ListByFeedback(feedbackID uint, includeInternal bool) ([]models.Comment, error)
+	UpdateBody(comment *models.Comment, editorID uint, body string, now time.Time) error
+}
+
+type BoardStore interface {
+	SavePost(post *models.Post) error
+	FindPostByFeedback(feedbackID uint) (*models.Post, error)
+	FindPost(id uint, filter PostFilter) (*models.Post, error)
+	ListPosts(filter PostFilter) ([]models.Post, int64, error)
+	AddVote(postID uint, userID uint, now time.Time, since time.Time, limit int64) (added bool, first bool, err error)
+	RemoveVote(postID uint, userID uint) error
+	Subscribe(postID uint, userID uint) error
+	Unsubscribe(postID uint, userID uint) error
+	Subscribers(postID uint) ([]models.User, error)
+	Activity(userID uint) (voted []uint, subscribed []uint, err error)
+	CreateComment(comment *models.PostComment) error
+	ListComments(postID uint) ([]models.PostComment, error)
+	RecentComments(limit int) ([]models.PostComment, error)
+	DeleteComment(id uint) (bool, error)
+}
+
+type ReleaseStore interface {
+	Create(release *models.Release) error
+	Update(release *models.Release) error
+	FindByID(id uint) (*models.Release, error)
+	FindByVersion(version string) (*models.Release, error)
+	List(publishedOnly bool, limit int, offset int) ([]models.Release, int64, error)
+	ReplaceFeedback(release *models.Release, feedbackIDs []uint) error
+	Publish(release *models.Release, now time.Time) (bool, error)
+}
+
+type SiteStore interface {
+	Create(site *models.Site) error
+	Update(site *models.Site) error
+	FindByID(id uint) (*models.Site, error)
+	FindByPublicKey(key string) (*models.Site, error)
+	All() ([]models.Site, error)
+}
+
+type TagStore interface {
+	All() ([]models.Tag, error)
+	FindOrCreate(names []string) ([]models.Tag, error)
+}
+
+type EmailStore interface {
+	Enqueue(email *models.OutboundEmail) error
+	ClaimDue(now time.Time, limit int, lease time.Duration) ([]models.OutboundEmail, error)
+	MarkSent(id uint, now time.Time) error
+	MarkRetry(id uint, lastError string, nextAttemptAt time.Time) error
+	MarkFailed(id uint, lastError string) error
+	Requeue(id uint, now time.Time) error
+	List(status string, limit int, offset int) ([]models.OutboundEmail, int64, error)
+	CountByStatus() (map[string]int64, error)
+}
+
+type HealthStore interface {
+	Ping(ctx context.Context) error
+	SchemaVersion() (version uint, dirty bool, err error)
+}
+
+var (
+	_ UserStore      = (*UserRepository)(nil)
+	_ MagicLinkStore = (*MagicLinkRepository)(nil)
+	_ FeedbackStore  = (*FeedbackRepository)(nil)
+	_ TagStore       = (*TagRepository)(nil)
+	_ CommentStore   = (*CommentRepository)(nil)
+	_ BoardStore     = (*BoardRepository)(nil)
+	_ ReleaseStore   = (*ReleaseRepository)(nil)
+	_ SiteStore      = (*SiteRepository)(nil)
+	_ EmailStore     = (*EmailRepository)(nil)
+	_ HealthStore    = (*HealthRepository)(nil)
+)
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"feedback-app/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+func (r *TagRepository) All() ([]models.Tag, error) {
+	var tags []models.Tag
+	err := r.db.Order("name").Find(&tags).Error
+	return tags, err
+}
+
+// FindOrCreate returns the tags with the given names, creating missing ones.
+func (r *TagRepository) FindOrCreate(names []string) ([]models.Tag, error) {
+	if len(names) == 0 {
+		return []models.Tag{}, nil
+	}
+
+	now := time.Now()
+	tags := make([]models.Tag, 0, len(names))
+	for _, name := range names {
+		tags = append(tags, models.Tag{Name: name, CreatedAt: now})
+	}
+
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	var existing []models.Tag
+	if err := r.db.Where("name IN ?", names).Order("name").Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
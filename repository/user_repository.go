@@ -34,3 +34,32 @@ func (r *UserRepository) FindByID(id uint) (*models.User, error) {
 	}
 	return &user, nil
 }
+
+// SearchByEmail returns users whose email contains query.
+func (r *UserRepository) SearchByEmail(query string, limit int) ([]models.User, error) {
+	var users []models.User
+	err := readReplica(r.db).Where("email LIKE ? ESCAPE '!'", "%"+escapeLike(query)+"%").Order("email").Limit(limit).Find(&users).Error
+	return users, err
+}
+
+func (r *UserRepository) UpdateRole(id uint, role string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Update("role", role).Error
+}
+
+func (r *UserRepository) UpdateLocale(id uint, locale string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Update("locale", locale).Error
+}
+
+func (r *UserRepository) UpdateNotifications(id uint, onReply bool, onStatus bool) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"notify_on_reply":  onReply,
+		"notify_on_status": onStatus,
+	}).Error
+}
+
+// DeleteByEmailSuffix permanently removes users whose email ends with suffix,
+// along with their magic links and feedback (via ON DELETE CASCADE).
+func (r *UserRepository) DeleteByEmailSuffix(suffix string) (int64, error) {
+	result := r.db.Unscoped().Where("email LIKE ? ESCAPE '!'", "%"+escapeLike(suffix)).Delete(&models.User{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"errors"
+	"feedback-app/i18n"
+	"feedback-app/models"
+	"feedback-app/repository"
+
+	"gorm.io/gorm"
+)
+
+// AccountService manages the signed-in user's own profile and preferences.
+type AccountService struct {
+	userRepo repository.UserStore
+}
+
+func NewAccountService(uRepo repository.UserStore) *AccountService {
+	return &AccountService{userRepo: uRepo}
+}
+
+// AccountUpdate holds the fields a user may change. Nil fields are left as
+// they are.
+type AccountUpdate struct {
+	Locale         *string
+	NotifyOnReply  *bool
+	NotifyOnStatus *bool
+}
+
+func (s *AccountService) Get(userID uint) (*models.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, NotFound("User not found")
+	}
+	return user, err
+}
+
+// Update applies update to the user. An empty locale clears the preference,
+// so emails follow the language of the device that requested them.
+func (s *AccountService) Update(userID uint, update AccountUpdate) (*models.User, error) {
+	if update.Locale != nil && *update.Locale != "" && !i18n.IsSupported(*update.Locale) {
+		return nil, Invalid("Unsupported locale")
+	}
+
+	user, err := s.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Locale != nil {
+		if err := s.userRepo.UpdateLocale(user.ID, *update.Locale); err != nil {
+			return nil, err
+		}
+		user.Locale = *update.Locale
+	}
+
+	if update.NotifyOnReply != nil || update.NotifyOnStatus != nil {
+		if update.NotifyOnReply != nil {
+			user.NotifyOnReply = *update.NotifyOnReply
+		}
+		if update.NotifyOnStatus != nil {
+			user.NotifyOnStatus = *update.NotifyOnStatus
+		}
+		if err := s.userRepo.UpdateNotifications(user.ID, user.NotifyOnReply, user.NotifyOnStatus); err != nil {
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"errors"
+	"feedback-app/models"
+	"feedback-app/repository"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const maxTagLength = 50
+
+type AdminService struct {
+	feedbackRepo repository.FeedbackStore
+	tagRepo      repository.TagStore
+	userRepo     repository.UserStore
+	emailRepo    repository.EmailStore
+	commentRepo  repository.CommentStore
+	notifier     *NotificationService
+}
+
+func NewAdminService(fRepo repository.FeedbackStore, tRepo repository.TagStore, uRepo repository.UserStore, eRepo repository.EmailStore, cRepo repository.CommentStore, notifier *NotificationService) *AdminService {
+	return &AdminService{
+		feedbackRepo: fRepo,
+		tagRepo:      tRepo,
+		userRepo:     uRepo,
+		emailRepo:    eRepo,
+		commentRepo:  cRepo,
+		notifier:     notifier,
+	}
+}
+
+// DailyCount is the number of feedback items received on a given day.
+type DailyCount struct {
+	Day   time.Time
+	Count int
+}
+
+// UserLookup bundles a user with the feedback they have submitted.
+type UserLookup struct {
+	User     models.User
+	Feedback []models.Feedback
+}
+
+func (s *AdminService) ListFeedback(filter repository.FeedbackFilter) ([]models.Feedback, int64, error) {
+	return s.feedbackRepo.List(filter)
+}
+
+func (s *AdminService) GetFeedback(id uint) (*models.Feedback, error) {
+	feedback, err := s.feedbackRepo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, NotFound("Feedback not found")
+	}
+	return feedback, err
+}
+
+func (s *AdminService) UpdateStatus(id uint, status string) error {
+	if !models.IsValidFeedbackStatus(status) {
+		return ErrInvalidStatus
+	}
+	feedback, err := s.GetFeedback(id)
+	if err != nil {
+		return err
+	}
+	if feedback.Status == status {
+		return nil
+	}
+	if err := s.feedbackRepo.UpdateStatus(id, status); err != nil {
+		return err
+	}
+
+	s.notifier.FeedbackStatusChanged(feedback, status)
+	return nil
+}
+
+// Approve releases guest feedback from moderation into the inbox.
+func (s *AdminService) Approve(id uint) error {
+	feedback, err := s.GetFeedback(id)
+	if err != nil {
+		return err
+	}
+	if feedback.Moderation == models.ModerationApproved {
+		return nil
+	}
+	return s.feedbackRepo.UpdateModeration(id, models.ModerationApproved)
+}
+
+// Reject deletes guest feedback that is waiting for moderation, such as
+// spam. Approved feedback cannot be rejected.
+func (s *AdminService) Reject(id uint) error {
+	feedback, err := s.GetFeedback(id)
+	if err != nil {
+		return err
+	}
+	if feedback.Moderation != models.ModerationPending {
+		return Invalid("Only feedback awaiting moderation can be rejected")
+	}
+	return s.feedbackRepo.Delete(id)
+}
+
+// Reply adds a staff comment to a feedback item. Public replies are sent to
+// the submitter; internal notes are only visible to staff.
+func (s *AdminService) Reply(feedbackID uint, authorID uint, body string, visibility string) error {
+	if visibility == "" {
+		visibility = models.CommentVisibilityPublic
+	}
+	if visibility != models.CommentVisibilityPublic && visibility != models.CommentVisibilityInternal {
+		return Invalid("Unknown comment visibility")
+	}
+	body, err := commentBody(body)
+	if err != nil {
+		return err
+	}
+
+	feedback, err := s.GetFeedback(feedbackID)
+	if err != nil {
+		return err
+	}
+
+	comment := &models.Comment{FeedbackID: feedback.ID, AuthorID: authorID, Body: body, Visibility: visibility}
+	if err := s.commentRepo.Create(comment); err != nil {
+		return err
+	}
+
+	if visibility == models.CommentVisibilityPublic {
+		s.notifier.FeedbackReplied(feedback, comment)
+	}
+	return nil
+}
+
+// EditComment changes the body of a comment staff member editorID wrote.
+func (s *AdminService) EditComment(feedbackID uint, commentID uint, editorID uint, body string) error {
+	_, err := editComment(s.commentRepo, feedbackID, commentID, editorID, body, true)
+	return err
+}
+
+// SetTags replaces the tags on a feedback item with the comma separated list
+// in raw. Tag names are trimmed, lower-cased and de-duplicated.
+func (s *AdminService) SetTags(id uint, raw string) error {
+	feedback, err := s.GetFeedback(id)
+	if err != nil {
+		return err
+	}
+
+	tags, err := s.tagRepo.FindOrCreate(ParseTags(raw))
+	if err != nil {
+		return err
+	}
+
+	return s.feedbackRepo.ReplaceTags(feedback, tags)
+}
+
+func (s *AdminService) AllTags() ([]models.Tag, error) {
+	return s.tagRepo.All()
+}
+
+// LookupUsers finds users by partial email and loads their recent feedback.
+func (s *AdminService) LookupUsers(query string) ([]UserLookup, error) {
+	users, err := s.userRepo.SearchByEmail(query, 20)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]UserLookup, 0, len(users))
+	for _, user := range users {
+		items, _, err := s.feedbackRepo.List(repository.FeedbackFilter{UserID: user.ID, Limit: 10})
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, UserLookup{User: user, Feedback: items})
+	}
+	return results, nil
+}
+
+// VolumeByDay returns one entry per day for the last days days, including
+// days without any feedback.
+func (s *AdminService) VolumeByDay(days int, now time.Time) ([]DailyCount, error) {
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	start := end.AddDate(0, 0, -(days - 1))
+
+	times, err := s.feedbackRepo.CreatedTimesSince(start)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]DailyCount, days)
+	for i := range counts {
+		counts[i].Day = start.AddDate(0, 0, i)
+	}
+	for _, t := range times {
+		t = t.In(now.Location())
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, now.Location())
+		idx := int(math.Round(day.Sub(start).Hours() / 24))
+		if idx >= 0 && idx < days {
+			counts[idx].Count++
+		}
+	}
+	return counts, nil
+}
+
+func (s *AdminService) StatusCounts() (map[string]int64, error) {
+	return s.feedbackRepo.CountByStatus()
+}
+
+// ParseTags splits a comma separated tag list into normalised, unique names.
+func ParseTags(raw string) []string {
+	seen := make(map[string]bool)
+	names := []string{}
+	for _, part := range strings.Split(raw, ",") {
+		name := strings.ToLower(strings.TrimSpace(part))
+		if name == "" || len(name) > maxTagLength || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ListEmails returns a page of queued, sent or failed emails. An empty status
+// lists all of them.
+func (s *AdminService) ListEmails(status string, limit int, offset int) ([]models.OutboundEmail, int64, error) {
+	return s.emailRepo.List(status, limit, offset)
+}
+
+func (s *AdminService) EmailCounts() (map[string]int64, error) {
+	return s.emailRepo.CountByStatus()
+}
+
+// RetryEmail puts a failed email back in the queue. Emails with an expiry,
+// such as login links, cannot be retried.
+func (s *AdminService) RetryEmail(id uint) error {
+	err := s.emailRepo.Requeue(id, time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return NotFound("Failed email not found or expired")
+	}
+	return err
+}
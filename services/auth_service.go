@@ -1,18 +1,15 @@
 package services
 
 import (
-	"bytes"
 	"errors"
+	"feedback-app/i18n"
 	"feedback-app/models"
 	"feedback-app/platform/email"
 	"feedback-app/repository"
 	"feedback-app/utils"
 	"fmt"
-	"html/template"
 	"log"
 	"net/url"
-	"os"
-	"path/filepath"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,9 +17,10 @@ import (
 )
 
 type AuthService struct {
-	userRepo      *repository.UserRepository
-	magicLinkRepo *repository.MagicLinkRepository
+	userRepo      repository.UserStore
+	magicLinkRepo repository.MagicLinkStore
 	emailClient   email.Client
+	templates     *email.TemplateRegistry
 	jwtSecret     string
 	jwtExpiration time.Duration
 	appURL        string
@@ -40,11 +38,12 @@ type AuthConfig struct {
 	LoginLinkTTL  time.Duration
 }
 
-func NewAuthService(uRepo *repository.UserRepository, mRepo *repository.MagicLinkRepository, emailClient email.Client, cfg AuthConfig) *AuthService {
+func NewAuthService(uRepo repository.UserStore, mRepo repository.MagicLinkStore, emailClient email.Client, templates *email.TemplateRegistry, cfg AuthConfig) *AuthService {
 	return &AuthService{
 		userRepo:      uRepo,
 		magicLinkRepo: mRepo,
 		emailClient:   emailClient,
+		templates:     templates,
 		jwtSecret:     cfg.JWTSecret,
 		jwtExpiration: cfg.JWTExpiration,
 		appURL:        cfg.AppURL,
@@ -54,10 +53,13 @@ func NewAuthService(uRepo *repository.UserRepository, mRepo *repository.MagicLin
 	}
 }
 
-func (s *AuthService) RequestLogin(emailAddr string) error {
+// RequestLogin emails a login link to emailAddr, creating the user on first
+// login. The email is written in the user's stored locale, or requestLocale
+// (negotiated from the request) if they have none.
+func (s *AuthService) RequestLogin(emailAddr string, requestLocale string) error {
 	user, err := s.userRepo.FindByEmail(emailAddr)
 	if err != nil && errors.Is(err, gorm.ErrRecordNotFound) {
-		user = &models.User{Email: emailAddr, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+		user = &models.User{Email: emailAddr, Role: models.RoleUser, Locale: requestLocale, CreatedAt: time.Now(), UpdatedAt: time.Now()}
 		if err := s.userRepo.Create(user); err != nil {
 			return err
 		}
@@ -65,6 +67,30 @@ func (s *AuthService) RequestLogin(emailAddr string) error {
 		return err
 	}
 
+	return s.sendLoginLink(user, emailAddr, "/auth/verify", i18n.Preferred(user.Locale, requestLocale))
+}
+
+// RequestAdminLogin sends a dashboard login link to emailAddr if it belongs to
+// an admin. Unknown or non-admin addresses are ignored so the endpoint cannot
+// be used to enumerate staff accounts.
+func (s *AuthService) RequestAdminLogin(emailAddr string, requestLocale string) error {
+	user, err := s.userRepo.FindByEmail(emailAddr)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("Admin login requested for unknown email %s", emailAddr)
+			return nil
+		}
+		return err
+	}
+	if !user.IsAdmin() {
+		log.Printf("Admin login requested for non-admin user %d", user.ID)
+		return nil
+	}
+
+	return s.sendLoginLink(user, emailAddr, "/admin/auth/verify", i18n.Preferred(user.Locale, requestLocale))
+}
+
+func (s *AuthService) sendLoginLink(user *models.User, emailAddr string, verifyPath string, locale string) error {
 	token := uuid.New().String()
 	magicLink := &models.MagicLink{
 		UserID:    user.ID,
@@ -77,16 +103,17 @@ func (s *AuthService) RequestLogin(emailAddr string) error {
 		return err
 	}
 
-	link := fmt.Sprintf("%s/auth/verify?token=%s", s.appURL, token)
-	body, err := s.renderLoginEmail(link)
+	link := fmt.Sprintf("%s%s?token=%s", s.appURL, verifyPath, token)
+	msg, err := s.renderLoginEmail(emailAddr, link, locale)
 	if err != nil {
 		log.Printf("Failed to render login email: %v", err)
-		return errors.New("failed to render login email")
+		return fmt.Errorf("render login email: %w", err)
 	}
+	msg.ExpiresAt = magicLink.ExpiresAt
 
-	if err := s.emailClient.Send(emailAddr, "Login to Feedback App", body); err != nil {
+	if err := s.emailClient.Send(msg); err != nil {
 		log.Printf("Failed to send email to %s: %v", emailAddr, err)
-		return errors.New("failed to send login email")
+		return ErrEmailDelivery
 	}
 
 	return nil
@@ -96,13 +123,13 @@ func (s *AuthService) ExchangeLoginToken(token string) (string, error) {
 	link, err := s.magicLinkRepo.ConsumeByToken(token, time.Now())
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return "", errors.New("invalid token")
+			return "", ErrInvalidToken
 		}
 		if errors.Is(err, repository.ErrTokenUsed) {
-			return "", errors.New("token already used")
+			return "", ErrTokenUsed
 		}
 		if errors.Is(err, repository.ErrTokenExpired) {
-			return "", errors.New("token expired")
+			return "", ErrTokenExpired
 		}
 		return "", err
 	}
@@ -117,7 +144,7 @@ func (s *AuthService) ExchangeLoginToken(token string) (string, error) {
 
 func (s *AuthService) BuildRedirectURL(token string) (string, error) {
 	if s.deepLinkURL == "" {
-		return "", errors.New("redirect URL not configured")
+		return "", ErrRedirectUnavailable
 	}
 
 	parsed, err := url.Parse(s.deepLinkURL)
@@ -132,17 +159,9 @@ func (s *AuthService) BuildRedirectURL(token string) (string, error) {
 	return parsed.String(), nil
 }
 
-func (s *AuthService) renderLoginEmail(link string) (string, error) {
-	content, err := os.ReadFile(filepath.Join("templates", "email", "login.html"))
-	if err != nil {
-		return "", err
-	}
-
-	tmpl, err := template.New("login.html").Parse(string(content))
-	if err != nil {
-		return "", err
-	}
-
+// renderLoginEmail renders the login templates for locale into a multipart
+// message.
+func (s *AuthService) renderLoginEmail(to string, link string, locale string) (email.Message, error) {
 	data := struct {
 		Link          string
 		ExpiryMinutes int
@@ -151,10 +170,15 @@ func (s *AuthService) renderLoginEmail(link string) (string, error) {
 		ExpiryMinutes: int(s.LoginLinkTTL.Minutes()),
 	}
 
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", err
+	rendered, err := s.templates.Render("login", locale, data)
+	if err != nil {
+		return email.Message{}, err
 	}
 
-	return buf.String(), nil
+	return email.Message{
+		To:      to,
+		Subject: i18n.T(locale, "Login to Feedback App"),
+		Text:    rendered.Text,
+		HTML:    rendered.HTML,
+	}, nil
 }
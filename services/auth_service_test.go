@@ -0,0 +1,159 @@
+package services_test
+
+import (
+	"errors"
+	"feedback-app/models"
+	"feedback-app/platform/email"
+	"feedback-app/repository"
+	"feedback-app/services"
+	"feedback-app/templates"
+	"feedback-app/utils"
+	"io/fs"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const testJWTSecret = "test-secret"
+
+type authFixture struct {
+	service   *services.AuthService
+	sink      *email.CaptureSink
+	users     *repository.UserRepository
+	magicLink *repository.MagicLinkRepository
+}
+
+func newAuthFixture(t *testing.T) *authFixture {
+	t.Helper()
+
+	gormDB := newTestDB(t)
+
+	emailFS, err := fs.Sub(templates.FS, "email")
+	if err != nil {
+		t.Fatalf("email templates: %v", err)
+	}
+	registry, err := email.NewTemplateRegistry(emailFS)
+	if err != nil {
+		t.Fatalf("email templates: %v", err)
+	}
+
+	f := &authFixture{
+		sink:      email.NewCaptureSink("noreply@example.com"),
+		users:     repository.NewUserRepository(gormDB),
+		magicLink: repository.NewMagicLinkRepository(gormDB),
+	}
+	f.service = services.NewAuthService(f.users, f.magicLink, f.sink, registry, services.AuthConfig{
+		JWTSecret:     testJWTSecret,
+		JWTExpiration: time.Hour,
+		AppURL:        "https://feedback.example.com",
+		LoginLinkTTL:  15 * time.Minute,
+	})
+	return f
+}
+
+var loginLinkPattern = regexp.MustCompile(`https://feedback\.example\.com/auth/verify\?token=[0-9a-f-]+`)
+
+// loginToken returns the token from the last login email sent to addr.
+func (f *authFixture) loginToken(t *testing.T, addr string) string {
+	t.Helper()
+
+	messages := f.sink.Messages(addr)
+	if len(messages) == 0 {
+		t.Fatalf("no email sent to %s", addr)
+	}
+	link := loginLinkPattern.FindString(messages[len(messages)-1].Text)
+	if link == "" {
+		t.Fatalf("no login link in email:\n%s", messages[len(messages)-1].Text)
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("parse login link: %v", err)
+	}
+	return u.Query().Get("token")
+}
+
+func TestLoginFlow(t *testing.T) {
+	f := newAuthFixture(t)
+
+	if err := f.service.RequestLogin("ada@example.com", "en"); err != nil {
+		t.Fatalf("RequestLogin: %v", err)
+	}
+	user, err := f.users.FindByEmail("ada@example.com")
+	if err != nil {
+		t.Fatalf("user not created: %v", err)
+	}
+
+	jwtToken, err := f.service.ExchangeLoginToken(f.loginToken(t, "ada@example.com"))
+	if err != nil {
+		t.Fatalf("ExchangeLoginToken: %v", err)
+	}
+	claims, err := utils.ParseJWT(jwtToken, testJWTSecret)
+	if err != nil {
+		t.Fatalf("ParseJWT: %v", err)
+	}
+	if claims.UserID != user.ID {
+		t.Errorf("JWT for user %d, want %d", claims.UserID, user.ID)
+	}
+}
+
+func TestExchangeLoginTokenReused(t *testing.T) {
+	f := newAuthFixture(t)
+
+	if err := f.service.RequestLogin("ada@example.com", "en"); err != nil {
+		t.Fatalf("RequestLogin: %v", err)
+	}
+	token := f.loginToken(t, "ada@example.com")
+
+	if _, err := f.service.ExchangeLoginToken(token); err != nil {
+		t.Fatalf("first ExchangeLoginToken: %v", err)
+	}
+	if _, err := f.service.ExchangeLoginToken(token); !errors.Is(err, services.ErrTokenUsed) {
+		t.Errorf("second ExchangeLoginToken: got %v, want ErrTokenUsed", err)
+	}
+}
+
+func TestExchangeLoginTokenExpired(t *testing.T) {
+	f := newAuthFixture(t)
+
+	link := &models.MagicLink{UserID: 1, Token: "expired", ExpiresAt: time.Now().Add(-time.Minute), CreatedAt: time.Now()}
+	if err := f.magicLink.Create(link); err != nil {
+		t.Fatalf("create magic link: %v", err)
+	}
+
+	if _, err := f.service.ExchangeLoginToken("expired"); !errors.Is(err, services.ErrTokenExpired) {
+		t.Errorf("got %v, want ErrTokenExpired", err)
+	}
+}
+
+func TestExchangeLoginTokenUnknown(t *testing.T) {
+	f := newAuthFixture(t)
+
+	_, err := f.service.ExchangeLoginToken("missing")
+	if !errors.Is(err, services.ErrInvalidToken) {
+		t.Errorf("got %v, want ErrInvalidToken", err)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Error("repository error leaked to the caller")
+	}
+}
+
+func TestRequestAdminLoginIgnoresNonAdmins(t *testing.T) {
+	f := newAuthFixture(t)
+
+	user := &models.User{Email: "user@example.com", Role: models.RoleUser}
+	if err := f.users.Create(user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	for _, addr := range []string{"user@example.com", "nobody@example.com"} {
+		if err := f.service.RequestAdminLogin(addr, "en"); err != nil {
+			t.Errorf("RequestAdminLogin(%s): %v", addr, err)
+		}
+	}
+	if n := len(f.sink.Messages("")); n != 0 {
+		t.Errorf("sent %d emails, want none", n)
+	}
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+	"errors"
+	"feedback-app/models"
+	"feedback-app/repository"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultBoardPageSize = 20
+	maxBoardPageSize     = 100
+	maxPostTitleLength   = 200
+	recentBoardComments  = 50
+	roadmapColumnSize    = 50
+)
+
+// BoardConfig holds the vote counting rules of the public board.
+type BoardConfig struct {
+	// MinAccountAge is how old an account must be before its votes count.
+	// Votes from newer accounts are kept and start counting once the
+	// account is old enough.
+	MinAccountAge time.Duration
+	// VotesPerHour caps how many votes one user can cast per hour.
+	VotesPerHour   int
+	TrendingWindow time.Duration
+}
+
+// BoardQuery selects a page of board posts. Sort is one of the
+// repository.PostSort values and defaults to votes.
+type BoardQuery struct {
+	Sort   string
+	Status string
+	Limit  int
+	Offset int
+}
+
+// BoardActivity lists the posts a user voted on and subscribed to.
+type BoardActivity struct {
+	Voted      []uint `json:"voted"`
+	Subscribed []uint `json:"subscribed"`
+}
+
+// Roadmap groups the published posts that are planned, in progress or done,
+// each by votes.
+type Roadmap struct {
+	Planned    []models.Post `json:"planned"`
+	InProgress []models.Post `json:"in_progress"`
+	Done       []models.Post `json:"done"`
+}
+
+// BoardService runs the public feature-request board: staff publish
+// feedback as posts that signed-in users can vote on, discuss and subscribe
+// to.
+type BoardService struct {
+	boardRepo    repository.BoardStore
+	feedbackRepo repository.FeedbackStore
+	notifier     *NotificationService
+	cfg          BoardConfig
+}
+
+func NewBoardService(bRepo repository.BoardStore, fRepo repository.FeedbackStore, notifier *NotificationService, cfg BoardConfig) *BoardService {
+	return &BoardService{
+		boardRepo:    bRepo,
+		feedbackRepo: fRepo,
+		notifier:     notifier,
+		cfg:          cfg,
+	}
+}
+
+// List returns a page of published posts and the number of matching posts.
+func (s *BoardService) List(query BoardQuery) ([]models.Post, int64, error) {
+	switch query.Sort {
+	case "":
+		query.Sort = repository.PostSortVotes
+	case repository.PostSortVotes, repository.PostSortTrending, repository.PostSortNewest:
+	default:
+		return nil, 0, Invalid("Unknown sort order")
+	}
+	if query.Status != "" && !models.IsValidFeedbackStatus(query.Status) {
+		return nil, 0, ErrInvalidStatus
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultBoardPageSize
+	}
+	if query.Limit > maxBoardPageSize {
+		query.Limit = maxBoardPageSize
+	}
+	if query.Offset < 0 {
+		query.Offset = 0
+	}
+
+	filter := s.filter(true)
+	filter.Sort = query.Sort
+	filter.Status = query.Status
+	filter.Limit = query.Limit
+	filter.Offset = query.Offset
+	return s.boardRepo.ListPosts(filter)
+}
+
+// Roadmap returns up to roadmapColumnSize published posts per roadmap
+// status.
+func (s *BoardService) Roadmap() (*Roadmap, error) {
+	roadmap := &Roadmap{}
+	columns := map[string]*[]models.Post{
+		models.FeedbackStatusPlanned:    &roadmap.Planned,
+		models.FeedbackStatusInProgress: &roadmap.InProgress,
+		models.FeedbackStatusDone:       &roadmap.Done,
+	}
+	for status, column := range columns {
+		filter := s.filter(true)
+		filter.Status = status
+		filter.Sort = repository.PostSortVotes
+		filter.Limit = roadmapColumnSize
+		posts, _, err := s.boardRepo.ListPosts(filter)
+		if err != nil {
+			return nil, err
+		}
+		*column = posts
+	}
+	return roadmap, nil
+}
+
+// Get returns a published post.
+func (s *BoardService) Get(postID uint) (*models.Post, error) {
+	post, err := s.boardRepo.FindPost(postID, s.filter(true))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, NotFound("Post not found")
+	}
+	return post, err
+}
+
+func (s *BoardService) Comments(postID uint) ([]models.PostComment, error) {
+	if _, err := s.Get(postID); err != nil {
+		return nil, err
+	}
+	return s.boardRepo.ListComments(postID)
+}
+
+// Vote adds userID's vote to a post and, on their first vote, subscribes
+// them to it. Voting twice is not an error and does not count twice, but
+// every vote cast counts towards VotesPerHour, even if withdrawn. Voting
+// again on a post already voted for is allowed at the limit.
+func (s *BoardService) Vote(userID uint, postID uint) (*models.Post, error) {
+	if _, err := s.Get(postID); err != nil {
+		return nil, err
+	}
+
+	// Only a first vote subscribes, so revoting does not undo an
+	// unsubscribe.
+	now := time.Now()
+	_, first, err := s.boardRepo.AddVote(postID, userID, now, now.Add(-time.Hour), int64(s.cfg.VotesPerHour))
+	if errors.Is(err, repository.ErrVoteLimit) {
+		return nil, ErrRateLimited
+	}
+	if err != nil {
+		return nil, err
+	}
+	if first {
+		if err := s.boardRepo.Subscribe(postID, userID); err != nil {
+			return nil, err
+		}
+	}
+	return s.Get(postID)
+}
+
+// Unvote withdraws userID's vote. The subscription is kept.
+func (s *BoardService) Unvote(userID uint, postID uint) (*models.Post, error) {
+	if _, err := s.Get(postID); err != nil {
+		return nil, err
+	}
+	if err := s.boardRepo.RemoveVote(postID, userID); err != nil {
+		return nil, err
+	}
+	return s.Get(postID)
+}
+
+// Comment posts a public comment, subscribes its author and notifies the
+// other subscribers.
+func (s *BoardService) Comment(userID uint, postID uint, body string) (*models.PostComment, error) {
+	body, err := commentBody(body)
+	if err != nil {
+		return nil, err
+	}
+	post, err := s.Get(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &models.PostComment{PostID: post.ID, AuthorID: userID, Body: body}
+	if err := s.boardRepo.CreateComment(comment); err != nil {
+		return nil, err
+	}
+	if err := s.boardRepo.Subscribe(post.ID, userID); err != nil {
+		return nil, err
+	}
+
+	s.notifier.PostCommented(post, comment)
+	return comment, nil
+}
+
+func (s *BoardService) Subscribe(userID uint, postID uint) error {
+	if _, err := s.Get(postID); err != nil {
+		return err
+	}
+	return s.boardRepo.Subscribe(postID, userID)
+}
+
+func (s *BoardService) Unsubscribe(userID uint, postID uint) error {
+	if _, err := s.Get(postID); err != nil {
+		return err
+	}
+	return s.boardRepo.Unsubscribe(postID, userID)
+}
+
+func (s *BoardService) Activity(userID uint) (*BoardActivity, error) {
+	voted, subscribed, err := s.boardRepo.Activity(userID)
+	if err != nil {
+		return nil, err
+	}
+	activity := &BoardActivity{Voted: voted, Subscribed: subscribed}
+	if activity.Voted == nil {
+		activity.Voted = []uint{}
+	}
+	if activity.Subscribed == nil {
+		activity.Subscribed = []uint{}
+	}
+	return activity, nil
+}
+
+// PostForFeedback returns the post made from a feedback item, published or
+// not, or nil if it was never published.
+func (s *BoardService) PostForFeedback(feedbackID uint) (*models.Post, error) {
+	post, err := s.boardRepo.FindPostByFeedback(feedbackID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return post, err
+}
+
+// Publish puts a feedback item on the board under the given public title
+// and body, or updates and republishes its existing post.
+func (s *BoardService) Publish(feedbackID uint, title string, body string) error {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return Invalid("Title is required")
+	}
+	if utf8.RuneCountInString(title) > maxPostTitleLength {
+		return Invalidf("Titles may be at most %d characters", maxPostTitleLength)
+	}
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return Invalid("Body is required")
+	}
+
+	feedback, err := s.feedbackRepo.FindByID(feedbackID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return NotFound("Feedback not found")
+	}
+	if err != nil {
+		return err
+	}
+	if feedback.Moderation == models.ModerationPending {
+		return Invalid("Approve guest feedback before publishing it")
+	}
+
+	post, err := s.PostForFeedback(feedback.ID)
+	if err != nil {
+		return err
+	}
+	if post == nil {
+		post = &models.Post{FeedbackID: feedback.ID}
+	}
+	post.Title = title
+	post.Body = body
+	if post.PublishedAt == nil {
+		now := time.Now()
+		post.PublishedAt = &now
+	}
+	return s.boardRepo.SavePost(post)
+}
+
+// Unpublish hides a post from the board. Its votes, comments and
+// subscriptions are kept for when it is published again.
+func (s *BoardService) Unpublish(feedbackID uint) error {
+	post, err := s.PostForFeedback(feedbackID)
+	if err != nil || post == nil || post.PublishedAt == nil {
+		return err
+	}
+	post.PublishedAt = nil
+	return s.boardRepo.SavePost(post)
+}
+
+// PublishedPosts returns the published posts made from the given feedback
+// items, keyed by feedback ID.
+func (s *BoardService) PublishedPosts(feedbackIDs []uint) (map[uint]models.Post, error) {
+	filter := s.filter(true)
+	filter.FeedbackIDs = feedbackIDs
+	posts, _, err := s.boardRepo.ListPosts(filter)
+	if err != nil {
+		return nil, err
+	}
+	byFeedback := make(map[uint]models.Post, len(posts))
+	for _, post := range posts {
+		byFeedback[post.FeedbackID] = post
+	}
+	return byFeedback, nil
+}
+
+// AllPosts returns every post, published or not, by votes.
+func (s *BoardService) AllPosts() ([]models.Post, error) {
+	filter := s.filter(false)
+	filter.Sort = repository.PostSortVotes
+	posts, _, err := s.boardRepo.ListPosts(filter)
+	return posts, err
+}
+
+func (s *BoardService) RecentComments() ([]models.PostComment, error) {
+	return s.boardRepo.RecentComments(recentBoardComments)
+}
+
+// DeleteComment removes a board comment, for moderation.
+func (s *BoardService) DeleteComment(commentID uint) error {
+	deleted, err := s.boardRepo.DeleteComment(commentID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return NotFound("Comment not found")
+	}
+	return nil
+}
+
+func (s *BoardService) filter(publishedOnly bool) repository.PostFilter {
+	now := time.Now()
+	return repository.PostFilter{
+		PublishedOnly: publishedOnly,
+		CountedBefore: now.Add(-s.cfg.MinAccountAge),
+		TrendingSince: now.Add(-s.cfg.TrendingWindow),
+	}
+}
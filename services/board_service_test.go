@@ -0,0 +1,61 @@
+package services_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"feedback-app/models"
+	"feedback-app/repository"
+	"feedback-app/services"
+)
+
+func TestVoteLimit(t *testing.T) {
+	gormDB := newTestDB(t)
+	user, _ := seedFeedback(t, gormDB, "ann@example.com")
+	board := services.NewBoardService(repository.NewBoardRepository(gormDB), repository.NewFeedbackRepository(gormDB), nil, services.BoardConfig{
+		VotesPerHour:   2,
+		TrendingWindow: 7 * 24 * time.Hour,
+	})
+
+	published := time.Now().Add(-time.Hour)
+	posts := make([]*models.Post, 3)
+	for i := range posts {
+		feedback := &models.Feedback{UserID: &user.ID, Content: "Idea"}
+		if err := gormDB.Create(feedback).Error; err != nil {
+			t.Fatalf("create feedback: %v", err)
+		}
+		posts[i] = &models.Post{FeedbackID: feedback.ID, Title: "Idea", Body: "Idea", PublishedAt: &published}
+		if err := gormDB.Create(posts[i]).Error; err != nil {
+			t.Fatalf("create post: %v", err)
+		}
+	}
+
+	for _, post := range posts[:2] {
+		if _, err := board.Vote(user.ID, post.ID); err != nil {
+			t.Fatalf("Vote(%d): %v", post.ID, err)
+		}
+	}
+	if _, err := board.Vote(user.ID, posts[2].ID); !errors.Is(err, services.ErrRateLimited) {
+		t.Fatalf("vote over the limit = %v, want ErrRateLimited", err)
+	}
+
+	// Revoting a post already voted for changes nothing, so the limit does
+	// not apply.
+	post, err := board.Vote(user.ID, posts[0].ID)
+	if err != nil {
+		t.Fatalf("revote at the limit: %v", err)
+	}
+	if post.Votes != 1 {
+		t.Fatalf("votes = %d, want 1", post.Votes)
+	}
+
+	// A withdrawn vote still counted, so voting again after withdrawing it
+	// is a new vote and over the limit.
+	if _, err := board.Unvote(user.ID, posts[0].ID); err != nil {
+		t.Fatalf("Unvote: %v", err)
+	}
+	if _, err := board.Vote(user.ID, posts[0].ID); !errors.Is(err, services.ErrRateLimited) {
+		t.Fatalf("vote after withdrawing at the limit = %v, want ErrRateLimited", err)
+	}
+}
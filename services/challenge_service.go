@@ -0,0 +1,112 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"feedback-app/repository"
+	"feedback-app/utils"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// challengePurpose separates challenge signatures from other uses of the
+// signing secret.
+const challengePurpose = "challenge"
+
+const maxSolutionLength = 64
+
+// Challenge is a proof-of-work puzzle for guest submissions: find a
+// solution such that SHA-256(token + ":" + solution) starts with Difficulty
+// zero bits.
+type Challenge struct {
+	Token      string    `json:"challenge"`
+	Difficulty int       `json:"difficulty"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// ChallengeService issues and checks signed proof-of-work challenges. They
+// are stateless until used; redemptions are stored in the database until the
+// challenge expires, so each can be redeemed once however many instances run.
+type ChallengeService struct {
+	redemptions repository.ChallengeStore
+	secret      string
+	difficulty  int
+	ttl         time.Duration
+}
+
+func NewChallengeService(redemptions repository.ChallengeStore, secret string, difficulty int, ttl time.Duration) *ChallengeService {
+	return &ChallengeService{
+		redemptions: redemptions,
+		secret:      secret,
+		difficulty:  difficulty,
+		ttl:         ttl,
+	}
+}
+
+// Issue returns a new challenge valid for the configured TTL.
+func (s *ChallengeService) Issue(now time.Time) (*Challenge, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	expiresAt := now.Add(s.ttl).Truncate(time.Second)
+	parts := []string{
+		strconv.Itoa(s.difficulty),
+		strconv.FormatInt(expiresAt.Unix(), 10),
+		base64.RawURLEncoding.EncodeToString(nonce),
+	}
+	token := strings.Join(parts, ".") + "." + utils.Sign(s.secret, append([]string{challengePurpose}, parts...)...)
+	return &Challenge{Token: token, Difficulty: s.difficulty, ExpiresAt: expiresAt}, nil
+}
+
+// Redeem checks that token was issued here, has not expired or been used,
+// and that solution solves it, then marks it used.
+func (s *ChallengeService) Redeem(token string, solution string, now time.Time) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 || len(solution) > maxSolutionLength {
+		return ErrInvalidChallenge
+	}
+	if !utils.VerifySignature(s.secret, parts[3], challengePurpose, parts[0], parts[1], parts[2]) {
+		return ErrInvalidChallenge
+	}
+	difficulty, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return ErrInvalidChallenge
+	}
+	expiry, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || now.After(time.Unix(expiry, 0)) {
+		return ErrInvalidChallenge
+	}
+	if leadingZeroBits(sha256.Sum256([]byte(token+":"+solution))) < difficulty {
+		return ErrInvalidChallenge
+	}
+
+	if err := s.redemptions.DeleteExpired(now); err != nil {
+		return err
+	}
+	sum := sha256.Sum256([]byte(token))
+	redeemed, err := s.redemptions.Redeem(hex.EncodeToString(sum[:]), time.Unix(expiry, 0), now)
+	if err != nil {
+		return err
+	}
+	if !redeemed {
+		return ErrInvalidChallenge
+	}
+	return nil
+}
+
+func leadingZeroBits(sum [sha256.Size]byte) int {
+	zeros := 0
+	for _, b := range sum {
+		if b != 0 {
+			return zeros + bits.LeadingZeros8(b)
+		}
+		zeros += 8
+	}
+	return zeros
+}
@@ -0,0 +1,96 @@
+package services_test
+
+import (
+	"errors"
+	"feedback-app/db"
+	"feedback-app/platform/slack"
+	"feedback-app/repository"
+	"feedback-app/services"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	gormDB, err := db.InitSQLite("file::memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		t.Fatalf("sqlite handle: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	return gormDB
+}
+
+func TestRedeemOnceAcrossInstances(t *testing.T) {
+	gormDB := newTestDB(t)
+	// Two instances sharing a database, as behind a load balancer.
+	first := services.NewChallengeService(repository.NewChallengeRepository(gormDB), testJWTSecret, 0, 10*time.Minute)
+	second := services.NewChallengeService(repository.NewChallengeRepository(gormDB), testJWTSecret, 0, 10*time.Minute)
+
+	now := time.Now()
+	challenge, err := first.Issue(now)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if err := first.Redeem(challenge.Token, "0", now); err != nil {
+		t.Fatalf("Redeem: %v", err)
+	}
+	if err := second.Redeem(challenge.Token, "0", now); !errors.Is(err, services.ErrInvalidChallenge) {
+		t.Fatalf("replay on another instance = %v, want ErrInvalidChallenge", err)
+	}
+	if err := first.Redeem(challenge.Token, "0", now); !errors.Is(err, services.ErrInvalidChallenge) {
+		t.Fatalf("replay = %v, want ErrInvalidChallenge", err)
+	}
+}
+
+func TestRedeemRejectsExpiredAndUnsolved(t *testing.T) {
+	gormDB := newTestDB(t)
+	challenges := services.NewChallengeService(repository.NewChallengeRepository(gormDB), testJWTSecret, 28, time.Minute)
+
+	now := time.Now()
+	challenge, err := challenges.Issue(now)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if err := challenges.Redeem(challenge.Token, "0", now.Add(2*time.Minute)); !errors.Is(err, services.ErrInvalidChallenge) {
+		t.Fatalf("expired challenge = %v, want ErrInvalidChallenge", err)
+	}
+	if err := challenges.Redeem(challenge.Token, "0", now); !errors.Is(err, services.ErrInvalidChallenge) {
+		t.Fatalf("unsolved challenge = %v, want ErrInvalidChallenge", err)
+	}
+	if err := challenges.Redeem(challenge.Token+"x", "0", now); !errors.Is(err, services.ErrInvalidChallenge) {
+		t.Fatalf("tampered challenge = %v, want ErrInvalidChallenge", err)
+	}
+}
+
+func TestSubmitGuestKeepsChallengeOnInvalidFeedback(t *testing.T) {
+	gormDB := newTestDB(t)
+	challenges := services.NewChallengeService(repository.NewChallengeRepository(gormDB), testJWTSecret, 0, 10*time.Minute)
+	feedback := services.NewFeedbackService(repository.NewFeedbackRepository(gormDB), slack.NewMockClient(), challenges)
+
+	challenge, err := challenges.Issue(time.Now())
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	input := services.GuestFeedbackInput{
+		FeedbackInput: services.FeedbackInput{Content: "Checkout is slow", Category: "nonsense"},
+		Email:         "guest@example.com",
+		Challenge:     challenge.Token,
+		Solution:      "0",
+	}
+	if err := feedback.SubmitGuest(input); !errors.Is(err, services.ErrInvalidRequest) {
+		t.Fatalf("SubmitGuest with unknown category = %v, want ErrInvalidRequest", err)
+	}
+
+	input.Category = ""
+	if err := feedback.SubmitGuest(input); err != nil {
+		t.Fatalf("SubmitGuest with the same challenge after fixing the input: %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"errors"
+	"feedback-app/models"
+	"feedback-app/repository"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+const maxCommentLength = 5000
+
+// CommentService is the submitter's side of the conversation on their
+// feedback. Staff use AdminService, which also sees internal notes.
+type CommentService struct {
+	feedbackRepo repository.FeedbackStore
+	commentRepo  repository.CommentStore
+}
+
+func NewCommentService(fRepo repository.FeedbackStore, cRepo repository.CommentStore) *CommentService {
+	return &CommentService{
+		feedbackRepo: fRepo,
+		commentRepo:  cRepo,
+	}
+}
+
+// List returns the public comments on feedback owned by userID.
+func (s *CommentService) List(userID uint, feedbackID uint) ([]models.Comment, error) {
+	if _, err := s.ownFeedback(userID, feedbackID); err != nil {
+		return nil, err
+	}
+	return s.commentRepo.ListByFeedback(feedbackID, false)
+}
+
+// Add posts a public comment by userID on their own feedback.
+func (s *CommentService) Add(userID uint, feedbackID uint, body string) (*models.Comment, error) {
+	body, err := commentBody(body)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.ownFeedback(userID, feedbackID); err != nil {
+		return nil, err
+	}
+
+	comment := &models.Comment{
+		FeedbackID: feedbackID,
+		AuthorID:   userID,
+		Body:       body,
+		Visibility: models.CommentVisibilityPublic,
+	}
+	if err := s.commentRepo.Create(comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// Edit changes the body of a comment userID wrote on their own feedback.
+func (s *CommentService) Edit(userID uint, feedbackID uint, commentID uint, body string) (*models.Comment, error) {
+	if _, err := s.ownFeedback(userID, feedbackID); err != nil {
+		return nil, err
+	}
+	return editComment(s.commentRepo, feedbackID, commentID, userID, body, false)
+}
+
+// ownFeedback loads feedback and checks that userID submitted it. Someone
+// else's feedback is reported as missing so IDs cannot be probed.
+func (s *CommentService) ownFeedback(userID uint, feedbackID uint) (*models.Feedback, error) {
+	feedback, err := s.feedbackRepo.FindByID(feedbackID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && feedback.SubmitterID() != userID) {
+		return nil, NotFound("Feedback not found")
+	}
+	return feedback, err
+}
+
+// editComment replaces the body of a comment on feedbackID, which only its
+// author may do. The previous text is kept in the comment's edit history.
+// Without includeInternal, internal notes are reported as missing.
+func editComment(repo repository.CommentStore, feedbackID uint, commentID uint, editorID uint, body string, includeInternal bool) (*models.Comment, error) {
+	body, err := commentBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := repo.FindByID(commentID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !commentVisible(comment, feedbackID, includeInternal)) {
+		return nil, NotFound("Comment not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if comment.AuthorID != editorID {
+		return nil, Forbidden("Only the author can edit a comment")
+	}
+	if comment.Body == body {
+		return comment, nil
+	}
+
+	if err := repo.UpdateBody(comment, editorID, body, time.Now()); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+func commentVisible(comment *models.Comment, feedbackID uint, includeInternal bool) bool {
+	if comment.FeedbackID != feedbackID {
+		return false
+	}
+	return includeInternal || comment.Visibility != models.CommentVisibilityInternal
+}
+
+func commentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", Invalid("Comment is required")
+	}
+	if utf8.RuneCountInString(body) > maxCommentLength {
+		return "", Invalidf("Comments may be at most %d characters", maxCommentLength)
+	}
+	return body, nil
+}
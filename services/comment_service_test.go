@@ -0,0 +1,130 @@
+package services_test
+
+import (
+	"errors"
+	"testing"
+
+	"feedback-app/models"
+	"feedback-app/repository"
+	"feedback-app/services"
+)
+
+func TestCommentService(t *testing.T) {
+	gormDB := newTestDB(t)
+	ann, annFeedback := seedFeedback(t, gormDB, "ann@example.com")
+	bob, bobFeedback := seedFeedback(t, gormDB, "bob@example.com")
+	staff := &models.User{Email: "staff@example.com", Role: models.RoleAdmin}
+	if err := gormDB.Create(staff).Error; err != nil {
+		t.Fatalf("create staff: %v", err)
+	}
+	comments := services.NewCommentService(repository.NewFeedbackRepository(gormDB), repository.NewCommentRepository(gormDB))
+
+	own, err := comments.Add(ann.ID, annFeedback.ID, "  It still fails  ")
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if own.Body != "It still fails" || own.Visibility != models.CommentVisibilityPublic {
+		t.Fatalf("comment = %+v, want a trimmed public comment", own)
+	}
+	reply := &models.Comment{FeedbackID: annFeedback.ID, AuthorID: staff.ID, Body: "Fixed in 1.2", Visibility: models.CommentVisibilityPublic}
+	note := &models.Comment{FeedbackID: annFeedback.ID, AuthorID: staff.ID, Body: "Customer is on the old plan", Visibility: models.CommentVisibilityInternal}
+	for _, comment := range []*models.Comment{reply, note} {
+		if err := gormDB.Create(comment).Error; err != nil {
+			t.Fatalf("create comment: %v", err)
+		}
+	}
+
+	t.Run("internal notes hidden", func(t *testing.T) {
+		list, err := comments.List(ann.ID, annFeedback.ID)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(list) != 2 {
+			t.Fatalf("List returned %d comments, want 2", len(list))
+		}
+		for _, comment := range list {
+			if comment.ID == note.ID {
+				t.Fatal("List returned the internal note")
+			}
+		}
+	})
+
+	t.Run("access", func(t *testing.T) {
+		tests := []struct {
+			name string
+			call func() error
+			want error
+		}{
+			{"list others' feedback", func() error {
+				_, err := comments.List(bob.ID, annFeedback.ID)
+				return err
+			}, services.ErrNotFound},
+			{"comment on others' feedback", func() error {
+				_, err := comments.Add(bob.ID, annFeedback.ID, "Me too")
+				return err
+			}, services.ErrNotFound},
+			{"edit on others' feedback", func() error {
+				_, err := comments.Edit(bob.ID, annFeedback.ID, own.ID, "Changed")
+				return err
+			}, services.ErrNotFound},
+			{"comment on missing feedback", func() error {
+				_, err := comments.Add(ann.ID, 999, "Hello")
+				return err
+			}, services.ErrNotFound},
+			{"edit staff reply", func() error {
+				_, err := comments.Edit(ann.ID, annFeedback.ID, reply.ID, "Not fixed")
+				return err
+			}, services.ErrForbidden},
+			{"edit internal note", func() error {
+				_, err := comments.Edit(ann.ID, annFeedback.ID, note.ID, "Changed")
+				return err
+			}, services.ErrNotFound},
+			{"edit under other feedback", func() error {
+				_, err := comments.Edit(bob.ID, bobFeedback.ID, own.ID, "Changed")
+				return err
+			}, services.ErrNotFound},
+			{"empty comment", func() error {
+				_, err := comments.Add(ann.ID, annFeedback.ID, "   ")
+				return err
+			}, services.ErrInvalidRequest},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if err := tt.call(); !errors.Is(err, tt.want) {
+					t.Fatalf("err = %v, want %v", err, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("edit history", func(t *testing.T) {
+		edited, err := comments.Edit(ann.ID, annFeedback.ID, own.ID, "It still fails on Safari")
+		if err != nil {
+			t.Fatalf("Edit: %v", err)
+		}
+		if edited.Body != "It still fails on Safari" || edited.EditedAt == nil {
+			t.Fatalf("edited comment = %+v", edited)
+		}
+		if _, err := comments.Edit(ann.ID, annFeedback.ID, own.ID, "It still fails on Safari 17"); err != nil {
+			t.Fatalf("second Edit: %v", err)
+		}
+		// Saving the same text again is not an edit.
+		if _, err := comments.Edit(ann.ID, annFeedback.ID, own.ID, "It still fails on Safari 17"); err != nil {
+			t.Fatalf("unchanged Edit: %v", err)
+		}
+
+		var edits []models.CommentEdit
+		if err := gormDB.Where("comment_id = ?", own.ID).Order("id").Find(&edits).Error; err != nil {
+			t.Fatalf("load edits: %v", err)
+		}
+		want := []string{"It still fails", "It still fails on Safari"}
+		if len(edits) != len(want) {
+			t.Fatalf("%d edits recorded, want %d", len(edits), len(want))
+		}
+		for i, edit := range edits {
+			if edit.Body != want[i] || edit.EditorID != ann.ID {
+				t.Errorf("edit %d = %+v, want body %q by %d", i, edit, want[i], ann.ID)
+			}
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"feedback-app/models"
+	"feedback-app/platform/email"
+	"feedback-app/repository"
+	"log"
+	"sync"
+	"time"
+)
+
+// emailLease is how long a claimed message is reserved for one worker. It
+// must comfortably exceed the SMTP dial and send timeouts; if a server dies
+// mid-send the message is retried once the lease runs out.
+const emailLease = 5 * time.Minute
+
+// maxEmailRetryDelay caps the exponential backoff between attempts.
+const maxEmailRetryDelay = time.Hour
+
+type EmailQueueConfig struct {
+	Workers      int
+	MaxAttempts  int
+	RetryBase    time.Duration
+	PollInterval time.Duration
+}
+
+// EmailQueue persists outgoing mail and delivers it from a pool of background
+// workers with exponential backoff. It implements email.Client, so callers
+// enqueue by calling Send and return without waiting for the SMTP server.
+type EmailQueue struct {
+	store  repository.EmailStore
+	sender email.Client
+	cfg    EmailQueueConfig
+	wake   chan struct{}
+}
+
+func NewEmailQueue(store repository.EmailStore, sender email.Client, cfg EmailQueueConfig) *EmailQueue {
+	return &EmailQueue{
+		store:  store,
+		sender: sender,
+		cfg:    cfg,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Send queues msg for delivery.
+func (q *EmailQueue) Send(msg email.Message) error {
+	item := &models.OutboundEmail{
+		Recipient:     msg.To,
+		Subject:       msg.Subject,
+		TextBody:      msg.Text,
+		HTMLBody:      msg.HTML,
+		Headers:       msg.Headers,
+		Status:        models.EmailStatusQueued,
+		NextAttemptAt: time.Now(),
+	}
+	if !msg.ExpiresAt.IsZero() {
+		item.ExpiresAt = &msg.ExpiresAt
+	}
+	if err := q.store.Enqueue(item); err != nil {
+		return err
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Run delivers queued mail until ctx is cancelled.
+func (q *EmailQueue) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < q.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (q *EmailQueue) work(ctx context.Context) {
+	for {
+		claimed, err := q.store.ClaimDue(time.Now(), 1, emailLease)
+		if err != nil {
+			log.Printf("Failed to claim queued email: %v", err)
+		}
+		for _, item := range claimed {
+			q.deliver(item)
+		}
+		if len(claimed) > 0 {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake:
+		case <-time.After(q.cfg.PollInterval):
+		}
+	}
+}
+
+func (q *EmailQueue) deliver(item models.OutboundEmail) {
+	if item.ExpiresAt != nil && !time.Now().Before(*item.ExpiresAt) {
+		log.Printf("Dropping email %d to %s, which expired before it could be sent", item.ID, item.Recipient)
+		if err := q.store.MarkFailed(item.ID, "expired before delivery"); err != nil {
+			log.Printf("Failed to mark email %d as failed: %v", item.ID, err)
+		}
+		return
+	}
+
+	err := q.sender.Send(email.Message{
+		To:      item.Recipient,
+		Subject: item.Subject,
+		Text:    item.TextBody,
+		HTML:    item.HTMLBody,
+		Headers: item.Headers,
+	})
+	if err == nil {
+		if err := q.store.MarkSent(item.ID, time.Now()); err != nil {
+			log.Printf("Failed to mark email %d as sent: %v", item.ID, err)
+		}
+		return
+	}
+
+	if item.Attempts >= q.cfg.MaxAttempts || email.IsPermanent(err) {
+		log.Printf("Giving up on email %d to %s after %d attempts: %v", item.ID, item.Recipient, item.Attempts, err)
+		if err := q.store.MarkFailed(item.ID, err.Error()); err != nil {
+			log.Printf("Failed to mark email %d as failed: %v", item.ID, err)
+		}
+		return
+	}
+
+	delay := emailRetryDelay(q.cfg.RetryBase, item.Attempts)
+	log.Printf("Failed to send email %d (attempt %d), retrying in %s: %v", item.ID, item.Attempts, delay, err)
+	if err := q.store.MarkRetry(item.ID, err.Error(), time.Now().Add(delay)); err != nil {
+		log.Printf("Failed to reschedule email %d: %v", item.ID, err)
+	}
+}
+
+// emailRetryDelay doubles base for every attempt after the first, up to
+// maxEmailRetryDelay.
+func emailRetryDelay(base time.Duration, attempt int) time.Duration {
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= maxEmailRetryDelay {
+			return maxEmailRetryDelay
+		}
+	}
+	return delay
+}
@@ -0,0 +1,213 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"feedback-app/models"
+	"feedback-app/platform/email"
+	"feedback-app/repository"
+	"feedback-app/services"
+
+	"gorm.io/gorm"
+)
+
+// failingSender counts the messages it is asked to send and fails each one
+// with err, if set.
+type failingSender struct {
+	mu    sync.Mutex
+	err   error
+	sends map[string]int
+}
+
+func (s *failingSender) Send(msg email.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sends == nil {
+		s.sends = make(map[string]int)
+	}
+	s.sends[msg.Subject]++
+	return s.err
+}
+
+func (s *failingSender) count(subject string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sends[subject]
+}
+
+// immediateRetryStore records the delay the queue asks for before each retry
+// but reschedules right away, so a test can walk through every attempt.
+type immediateRetryStore struct {
+	*repository.EmailRepository
+	mu     sync.Mutex
+	delays []time.Duration
+}
+
+func (s *immediateRetryStore) MarkRetry(id uint, lastError string, nextAttemptAt time.Time) error {
+	s.mu.Lock()
+	s.delays = append(s.delays, time.Until(nextAttemptAt).Round(time.Minute))
+	s.mu.Unlock()
+	return s.EmailRepository.MarkRetry(id, lastError, time.Now())
+}
+
+// runQueue runs queue until no email in gormDB is queued any more.
+func runQueue(t *testing.T, gormDB *gorm.DB, queue *services.EmailQueue) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		queue.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		var queued int64
+		if err := gormDB.Model(&models.OutboundEmail{}).Where("status = ?", models.EmailStatusQueued).Count(&queued).Error; err != nil {
+			t.Fatalf("count queued emails: %v", err)
+		}
+		if queued == 0 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("emails still queued after 5s")
+}
+
+func loadEmail(t *testing.T, gormDB *gorm.DB, subject string) models.OutboundEmail {
+	t.Helper()
+	var item models.OutboundEmail
+	if err := gormDB.Where("subject = ?", subject).First(&item).Error; err != nil {
+		t.Fatalf("load email: %v", err)
+	}
+	return item
+}
+
+func newQueueConfig(maxAttempts int) services.EmailQueueConfig {
+	return services.EmailQueueConfig{
+		Workers:      1,
+		MaxAttempts:  maxAttempts,
+		RetryBase:    time.Minute,
+		PollInterval: 5 * time.Millisecond,
+	}
+}
+
+func TestEmailQueueBackoffUntilMaxAttempts(t *testing.T) {
+	gormDB := newTestDB(t)
+	store := &immediateRetryStore{EmailRepository: repository.NewEmailRepository(gormDB)}
+	sender := &failingSender{err: errors.New("421 try again later")}
+	queue := services.NewEmailQueue(store, sender, newQueueConfig(8))
+
+	if err := queue.Send(email.Message{To: "ada@example.com", Subject: "Status changed", Text: "Hello"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	runQueue(t, gormDB, queue)
+
+	if sends := sender.count("Status changed"); sends != 8 {
+		t.Fatalf("sent %d times, want 8", sends)
+	}
+	want := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 16 * time.Minute, 32 * time.Minute, time.Hour}
+	if len(store.delays) != len(want) {
+		t.Fatalf("retry delays = %v, want %v", store.delays, want)
+	}
+	for i := range want {
+		if store.delays[i] != want[i] {
+			t.Fatalf("retry delays = %v, want %v", store.delays, want)
+		}
+	}
+
+	item := loadEmail(t, gormDB, "Status changed")
+	if item.Status != models.EmailStatusFailed || item.Attempts != 8 || item.LastError != "421 try again later" {
+		t.Fatalf("email = %+v, want failed after 8 attempts", item)
+	}
+	if item.TextBody == "" {
+		t.Fatal("body of a failed email without expiry was dropped; it can still be retried")
+	}
+}
+
+func TestEmailQueuePermanentError(t *testing.T) {
+	gormDB := newTestDB(t)
+	store := &immediateRetryStore{EmailRepository: repository.NewEmailRepository(gormDB)}
+	sender := &failingSender{err: &email.PermanentError{Err: errors.New("550 no such user")}}
+	queue := services.NewEmailQueue(store, sender, newQueueConfig(5))
+
+	if err := queue.Send(email.Message{To: "nobody@example.com", Subject: "Welcome", Text: "Hello"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	runQueue(t, gormDB, queue)
+
+	if sends := sender.count("Welcome"); sends != 1 {
+		t.Fatalf("sent %d times, want 1", sends)
+	}
+	if len(store.delays) != 0 {
+		t.Fatalf("retried after a permanent error: %v", store.delays)
+	}
+	if item := loadEmail(t, gormDB, "Welcome"); item.Status != models.EmailStatusFailed || item.Attempts != 1 {
+		t.Fatalf("email = %+v, want failed after 1 attempt", item)
+	}
+}
+
+func TestEmailQueueExpiredLoginEmail(t *testing.T) {
+	gormDB := newTestDB(t)
+	emails := repository.NewEmailRepository(gormDB)
+	sender := &failingSender{}
+	queue := services.NewEmailQueue(emails, sender, newQueueConfig(5))
+
+	expired := email.Message{To: "ada@example.com", Subject: "Expired login", Text: "token", ExpiresAt: time.Now().Add(-time.Second)}
+	valid := email.Message{To: "ada@example.com", Subject: "Login", Text: "token", ExpiresAt: time.Now().Add(time.Hour)}
+	for _, msg := range []email.Message{expired, valid} {
+		if err := queue.Send(msg); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+	runQueue(t, gormDB, queue)
+
+	if sends := sender.count("Expired login"); sends != 0 {
+		t.Fatalf("expired email sent %d times", sends)
+	}
+	item := loadEmail(t, gormDB, "Expired login")
+	if item.Status != models.EmailStatusFailed || item.TextBody != "" {
+		t.Fatalf("expired email = %+v, want failed without body", item)
+	}
+	admin := services.NewAdminService(nil, nil, nil, emails, nil, nil)
+	if err := admin.RetryEmail(item.ID); !errors.Is(err, services.ErrNotFound) {
+		t.Fatalf("RetryEmail of an expired login email = %v, want ErrNotFound", err)
+	}
+
+	if sends := sender.count("Login"); sends != 1 {
+		t.Fatalf("login email sent %d times, want 1", sends)
+	}
+	if item := loadEmail(t, gormDB, "Login"); item.Status != models.EmailStatusSent || item.TextBody != "" {
+		t.Fatalf("login email = %+v, want sent without body", item)
+	}
+}
+
+func TestEmailQueueWorkersDeliverOnce(t *testing.T) {
+	gormDB := newTestDB(t)
+	sender := &failingSender{}
+	cfg := newQueueConfig(5)
+	cfg.Workers = 4
+	queue := services.NewEmailQueue(repository.NewEmailRepository(gormDB), sender, cfg)
+
+	subjects := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}
+	for _, subject := range subjects {
+		if err := queue.Send(email.Message{To: "ada@example.com", Subject: subject, Text: "Hello"}); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+	runQueue(t, gormDB, queue)
+
+	for _, subject := range subjects {
+		if sends := sender.count(subject); sends != 1 {
+			t.Errorf("%q sent %d times, want 1", subject, sends)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package services
+
+import "fmt"
+
+// Error is a domain error with a stable, machine-readable code. Controllers
+// hand these to gin via ctx.Error and middleware.ErrorHandler turns them into
+// problem+json responses, so clients can branch on Code instead of Message.
+// Message is English and doubles as the i18n catalog key; Args fill its
+// placeholders after translation.
+type Error struct {
+	Code    string
+	Message string
+	Args    []interface{}
+}
+
+func (e *Error) Error() string {
+	if len(e.Args) == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf(e.Message, e.Args...)
+}
+
+// Is matches any *Error with the same code, so errors created with a custom
+// message still satisfy errors.Is against the sentinel values below.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+const (
+	CodeInvalidRequest      = "invalid_request"
+	CodeUnauthorized        = "unauthorized"
+	CodeForbidden           = "forbidden"
+	CodeNotFound            = "not_found"
+	CodeConflict            = "conflict"
+	CodeInvalidToken        = "invalid_token"
+	CodeTokenUsed           = "token_used"
+	CodeTokenExpired        = "token_expired"
+	CodeDuplicateFeedback   = "duplicate_feedback"
+	CodeInvalidChallenge    = "invalid_challenge"
+	CodeInvalidStatus       = "invalid_status"
+	CodeRateLimited         = "rate_limited"
+	CodeEmailDelivery       = "email_delivery_failed"
+	CodeRedirectUnavailable = "redirect_unavailable"
+	CodeInternal            = "internal_error"
+)
+
+var (
+	ErrInvalidRequest      = &Error{Code: CodeInvalidRequest, Message: "invalid request"}
+	ErrUnauthorized        = &Error{Code: CodeUnauthorized, Message: "authentication required"}
+	ErrForbidden           = &Error{Code: CodeForbidden, Message: "forbidden"}
+	ErrNotFound            = &Error{Code: CodeNotFound, Message: "resource not found"}
+	ErrConflict            = &Error{Code: CodeConflict, Message: "conflict with the current state"}
+	ErrInvalidToken        = &Error{Code: CodeInvalidToken, Message: "invalid token"}
+	ErrTokenUsed           = &Error{Code: CodeTokenUsed, Message: "token already used"}
+	ErrTokenExpired        = &Error{Code: CodeTokenExpired, Message: "token expired"}
+	ErrDuplicateFeedback   = &Error{Code: CodeDuplicateFeedback, Message: "duplicate feedback submission prevented"}
+	ErrInvalidChallenge    = &Error{Code: CodeInvalidChallenge, Message: "invalid, expired or unsolved challenge"}
+	ErrInvalidStatus       = &Error{Code: CodeInvalidStatus, Message: "invalid feedback status"}
+	ErrRateLimited         = &Error{Code: CodeRateLimited, Message: "rate limit exceeded, please try again later"}
+	ErrEmailDelivery       = &Error{Code: CodeEmailDelivery, Message: "failed to send email"}
+	ErrRedirectUnavailable = &Error{Code: CodeRedirectUnavailable, Message: "redirect URL not configured"}
+)
+
+// Invalid returns an ErrInvalidRequest with a specific message.
+func Invalid(message string) error {
+	return &Error{Code: CodeInvalidRequest, Message: message}
+}
+
+// Invalidf returns an ErrInvalidRequest whose message is formatted from
+// format and args once translated.
+func Invalidf(format string, args ...interface{}) error {
+	return &Error{Code: CodeInvalidRequest, Message: format, Args: args}
+}
+
+// Unauthorized returns an ErrUnauthorized with a specific message.
+func Unauthorized(message string) error {
+	return &Error{Code: CodeUnauthorized, Message: message}
+}
+
+// Forbidden returns an ErrForbidden with a specific message.
+func Forbidden(message string) error {
+	return &Error{Code: CodeForbidden, Message: message}
+}
+
+// NotFound returns an ErrNotFound with a specific message.
+func NotFound(message string) error {
+	return &Error{Code: CodeNotFound, Message: message}
+}
+
+// Conflict returns an ErrConflict with a specific message.
+func Conflict(message string) error {
+	return &Error{Code: CodeConflict, Message: message}
+}
@@ -1,49 +1,133 @@
 package services
 
 import (
-	"errors"
 	"feedback-app/models"
 	"feedback-app/platform/slack"
 	"feedback-app/repository"
 	"fmt"
+	"strings"
 	"time"
 )
 
 type FeedbackService struct {
-	repo        *repository.FeedbackRepository
+	repo        repository.FeedbackStore
 	slackClient slack.Client
+	challenges  *ChallengeService
 }
 
-func NewFeedbackService(repo *repository.FeedbackRepository, slackClient slack.Client) *FeedbackService {
+func NewFeedbackService(repo repository.FeedbackStore, slackClient slack.Client, challenges *ChallengeService) *FeedbackService {
 	return &FeedbackService{
 		repo:        repo,
 		slackClient: slackClient,
+		challenges:  challenges,
 	}
 }
 
-func (s *FeedbackService) SubmitFeedback(userID uint, content string) error {
-	isDuplicate, err := s.repo.CheckDuplicate(userID, content)
+// FeedbackInput is what a user submits. Category defaults to "other".
+type FeedbackInput struct {
+	Content  string
+	Category string
+	Metadata models.Metadata
+}
+
+// GuestFeedbackInput is feedback sent without an account. Challenge and
+// Solution are a solved proof-of-work challenge from
+// ChallengeService.Issue; Email is an optional, unverified contact address.
+// SiteID identifies the site whose embedded widget sent it, if any.
+type GuestFeedbackInput struct {
+	FeedbackInput
+	Email     string
+	Challenge string
+	Solution  string
+	SiteID    *uint
+}
+
+const (
+	maxMetadataEntries  = 20
+	maxMetadataKeyLen   = 64
+	maxMetadataValueLen = 256
+)
+
+func (s *FeedbackService) SubmitFeedback(userID uint, input FeedbackInput) error {
+	feedback, err := s.newFeedback(&userID, input)
 	if err != nil {
 		return err
 	}
-	if isDuplicate {
-		return errors.New("duplicate feedback submission prevented")
-	}
-
-	feedback := &models.Feedback{
-		UserID:    userID,
-		Content:   content,
-		CreatedAt: time.Now(),
-	}
 
 	if err := s.repo.Create(feedback); err != nil {
 		return err
 	}
 
 	go func() {
-		msg := fmt.Sprintf("New user feedback (User ID: %d): %s", userID, content)
+		msg := fmt.Sprintf("New user feedback (User ID: %d, %s): %s", userID, input.Category, input.Content)
 		_ = s.slackClient.PostMessage("feedbacks", msg)
 	}()
 
 	return nil
 }
+
+// Challenge issues a proof-of-work challenge for SubmitGuest.
+func (s *FeedbackService) Challenge() (*Challenge, error) {
+	return s.challenges.Issue(time.Now())
+}
+
+// SubmitGuest stores feedback from someone without an account. It is held
+// for moderation and only reaches the inbox once staff approve it. The
+// challenge is redeemed only once the feedback is valid, so a rejected
+// submission can be corrected and sent again with the same solution.
+func (s *FeedbackService) SubmitGuest(input GuestFeedbackInput) error {
+	feedback, err := s.newFeedback(nil, input.FeedbackInput)
+	if err != nil {
+		return err
+	}
+	if err := s.challenges.Redeem(input.Challenge, input.Solution, time.Now()); err != nil {
+		return err
+	}
+	feedback.GuestEmail = strings.TrimSpace(input.Email)
+	feedback.Moderation = models.ModerationPending
+	feedback.SiteID = input.SiteID
+
+	return s.repo.Create(feedback)
+}
+
+// newFeedback validates input and builds the feedback item. userID is nil
+// for guests, whose duplicates are detected across all guest feedback.
+func (s *FeedbackService) newFeedback(userID *uint, input FeedbackInput) (*models.Feedback, error) {
+	if input.Category == "" {
+		input.Category = models.FeedbackCategoryOther
+	}
+	if !models.IsValidFeedbackCategory(input.Category) {
+		return nil, Invalid("Unknown feedback category")
+	}
+	if err := validateMetadata(input.Metadata); err != nil {
+		return nil, err
+	}
+
+	isDuplicate, err := s.repo.CheckDuplicate(userID, input.Content)
+	if err != nil {
+		return nil, err
+	}
+	if isDuplicate {
+		return nil, ErrDuplicateFeedback
+	}
+
+	return &models.Feedback{
+		UserID:    userID,
+		Content:   input.Content,
+		Category:  input.Category,
+		Metadata:  input.Metadata,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+func validateMetadata(metadata models.Metadata) error {
+	if len(metadata) > maxMetadataEntries {
+		return Invalidf("Metadata may have at most %d entries", maxMetadataEntries)
+	}
+	for key, value := range metadata {
+		if key == "" || len(key) > maxMetadataKeyLen || len(value) > maxMetadataValueLen {
+			return Invalid("Metadata keys must be 1-64 characters and values at most 256 characters")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"feedback-app/repository"
+	"log"
+	"time"
+)
+
+type HealthService struct {
+	repo                  repository.HealthStore
+	expectedSchemaVersion uint
+}
+
+func NewHealthService(repo repository.HealthStore, expectedSchemaVersion uint) *HealthService {
+	return &HealthService{
+		repo:                  repo,
+		expectedSchemaVersion: expectedSchemaVersion,
+	}
+}
+
+// Readiness reports whether the instance can serve traffic.
+type Readiness struct {
+	Ready                 bool   `json:"ready"`
+	Database              string `json:"database"`
+	SchemaVersion         uint   `json:"schema_version"`
+	ExpectedSchemaVersion uint   `json:"expected_schema_version"`
+	SchemaDirty           bool   `json:"schema_dirty"`
+}
+
+// Readiness is ready when the database answers and its schema is clean and
+// at least as new as the migrations embedded in this build. A newer schema
+// is fine: it happens while older replicas drain during a rolling deploy.
+func (s *HealthService) Readiness(ctx context.Context) Readiness {
+	status := Readiness{
+		Database:              "ok",
+		ExpectedSchemaVersion: s.expectedSchemaVersion,
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	if err := s.repo.Ping(pingCtx); err != nil {
+		log.Printf("Readiness: database ping failed: %v", err)
+		status.Database = "unavailable"
+		return status
+	}
+
+	version, dirty, err := s.repo.SchemaVersion()
+	if err != nil {
+		log.Printf("Readiness: failed to read schema version: %v", err)
+		status.Database = "error"
+		return status
+	}
+
+	status.SchemaVersion = version
+	status.SchemaDirty = dirty
+	status.Ready = !dirty && version >= s.expectedSchemaVersion
+	return status
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"feedback-app/models"
+	"feedback-app/platform/email"
+	"feedback-app/repository"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// replyAddressPurpose separates reply address signatures from other uses of
+// the signing secret.
+const replyAddressPurpose = "reply"
+
+// replySignatureLength keeps reply addresses within the 64 character limit
+// on local parts while leaving over 128 bits of signature.
+const replySignatureLength = 26
+
+// replySignatureEncoding is case-insensitive once lowercased, since mail
+// servers may change the case of the local part on the way back.
+var replySignatureEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+const replyAddressPrefix = "reply+"
+
+// ReplyAddresses builds and checks per-thread reply addresses of the form
+// reply+<feedback>.<user>.<signature>@domain. The signature binds the
+// address to one submitter's thread, so it cannot be guessed or reused for
+// another. An empty domain disables replying by email.
+type ReplyAddresses struct {
+	secret string
+	domain string
+}
+
+func NewReplyAddresses(secret string, domain string) *ReplyAddresses {
+	return &ReplyAddresses{secret: secret, domain: strings.ToLower(domain)}
+}
+
+func (a *ReplyAddresses) Enabled() bool {
+	return a.domain != ""
+}
+
+// For returns the reply address for userID's thread on feedbackID.
+func (a *ReplyAddresses) For(feedbackID uint, userID uint) string {
+	feedback := strconv.FormatUint(uint64(feedbackID), 10)
+	user := strconv.FormatUint(uint64(userID), 10)
+	return replyAddressPrefix + feedback + "." + user + "." + a.sign(feedback, user) + "@" + a.domain
+}
+
+// Parse returns the thread named by address if it is a valid reply address.
+func (a *ReplyAddresses) Parse(address string) (feedbackID uint, userID uint, ok bool) {
+	at := strings.LastIndex(address, "@")
+	if !a.Enabled() || at < 0 || !strings.EqualFold(address[at+1:], a.domain) {
+		return 0, 0, false
+	}
+	local := strings.ToLower(address[:at])
+	if !strings.HasPrefix(local, replyAddressPrefix) {
+		return 0, 0, false
+	}
+
+	parts := strings.Split(local[len(replyAddressPrefix):], ".")
+	if len(parts) != 3 || !hmac.Equal([]byte(parts[2]), []byte(a.sign(parts[0], parts[1]))) {
+		return 0, 0, false
+	}
+	feedback, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	user, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return uint(feedback), uint(user), true
+}
+
+func (a *ReplyAddresses) sign(feedback string, user string) string {
+	mac := hmac.New(sha256.New, []byte(a.secret))
+	mac.Write([]byte(strings.Join([]string{replyAddressPurpose, feedback, user}, "\x00")))
+	return strings.ToLower(replySignatureEncoding.EncodeToString(mac.Sum(nil)))[:replySignatureLength]
+}
+
+// InboundEmailService turns emailed replies to notifications into comments
+// on the feedback thread named by the reply address.
+type InboundEmailService struct {
+	addresses *ReplyAddresses
+	userRepo  repository.UserStore
+	comments  *CommentService
+}
+
+func NewInboundEmailService(addresses *ReplyAddresses, uRepo repository.UserStore, comments *CommentService) *InboundEmailService {
+	return &InboundEmailService{
+		addresses: addresses,
+		userRepo:  uRepo,
+		comments:  comments,
+	}
+}
+
+// Receive parses the raw message and appends its new text, without quoted
+// parts, as a comment by the submitter. Automatic replies such as out of
+// office notices are dropped; Receive then returns a nil comment and no
+// error.
+func (s *InboundEmailService) Receive(raw io.Reader) (*models.Comment, error) {
+	msg, err := email.ParseInbound(raw)
+	if err != nil {
+		log.Printf("Rejected inbound email: %v", err)
+		return nil, Invalid("Invalid email message")
+	}
+	if msg.Automatic {
+		log.Printf("Ignored automatic inbound email from %s", msg.From)
+		return nil, nil
+	}
+
+	feedbackID, userID, ok := s.thread(msg.To)
+	if !ok {
+		return nil, NotFound("No feedback thread matches the recipient")
+	}
+
+	// The signed address already names the thread; also requiring the
+	// submitter's own address keeps a forwarded notification from letting
+	// someone else post in their name.
+	user, err := s.userRepo.FindByID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, NotFound("User not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(user.Email, msg.From) {
+		return nil, Forbidden("Sender does not match the feedback submitter")
+	}
+
+	return s.comments.Add(userID, feedbackID, email.StripQuoted(msg.Text))
+}
+
+func (s *InboundEmailService) thread(recipients []string) (uint, uint, bool) {
+	for _, recipient := range recipients {
+		if feedbackID, userID, ok := s.addresses.Parse(recipient); ok {
+			return feedbackID, userID, true
+		}
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,171 @@
+package services_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"feedback-app/models"
+	"feedback-app/repository"
+	"feedback-app/services"
+
+	"gorm.io/gorm"
+)
+
+const testReplyDomain = "reply.example.com"
+
+// seedFeedback creates a user and a piece of feedback they submitted.
+func seedFeedback(t *testing.T, gormDB *gorm.DB, address string) (*models.User, *models.Feedback) {
+	t.Helper()
+
+	user := &models.User{Email: address}
+	if err := gormDB.Create(user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	feedback := &models.Feedback{UserID: &user.ID, Content: "The export is broken"}
+	if err := gormDB.Create(feedback).Error; err != nil {
+		t.Fatalf("create feedback: %v", err)
+	}
+	return user, feedback
+}
+
+func TestReplyAddresses(t *testing.T) {
+	addresses := services.NewReplyAddresses(testJWTSecret, testReplyDomain)
+	address := addresses.For(12, 34)
+	local, _, _ := strings.Cut(address, "@")
+	if len(local) > 64 {
+		t.Fatalf("local part %q is longer than 64 characters", local)
+	}
+
+	tamperedSig := []byte(address)
+	at := strings.LastIndex(address, "@")
+	if tamperedSig[at-1] == 'a' {
+		tamperedSig[at-1] = 'b'
+	} else {
+		tamperedSig[at-1] = 'a'
+	}
+	sig := address[strings.LastIndex(local, ".")+1 : at]
+
+	tests := []struct {
+		name    string
+		address string
+		ok      bool
+	}{
+		{"valid", address, true},
+		{"uppercased", strings.ToUpper(address), true},
+		{"tampered signature", string(tamperedSig), false},
+		{"other feedback", "reply+13.34." + sig + "@" + testReplyDomain, false},
+		{"other user", "reply+12.35." + sig + "@" + testReplyDomain, false},
+		{"foreign domain", local + "@example.org", false},
+		{"subdomain", local + "@evil." + testReplyDomain, false},
+		{"missing prefix", strings.TrimPrefix(local, "reply+") + "@" + testReplyDomain, false},
+		{"no domain", local, false},
+		{"not numeric", "reply+a.b." + sig + "@" + testReplyDomain, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feedbackID, userID, ok := addresses.Parse(tt.address)
+			if ok != tt.ok {
+				t.Fatalf("Parse(%q) ok = %v, want %v", tt.address, ok, tt.ok)
+			}
+			if ok && (feedbackID != 12 || userID != 34) {
+				t.Fatalf("Parse(%q) = %d, %d, want 12, 34", tt.address, feedbackID, userID)
+			}
+		})
+	}
+
+	other := services.NewReplyAddresses("other-secret", testReplyDomain)
+	if _, _, ok := other.Parse(address); ok {
+		t.Fatal("address signed with another secret was accepted")
+	}
+	disabled := services.NewReplyAddresses(testJWTSecret, "")
+	if _, _, ok := disabled.Parse(address); ok {
+		t.Fatal("Parse accepted an address with replying disabled")
+	}
+}
+
+func TestReceive(t *testing.T) {
+	gormDB := newTestDB(t)
+	ann, annFeedback := seedFeedback(t, gormDB, "ann@example.com")
+	bob, _ := seedFeedback(t, gormDB, "bob@example.com")
+
+	addresses := services.NewReplyAddresses(testJWTSecret, testReplyDomain)
+	comments := services.NewCommentService(repository.NewFeedbackRepository(gormDB), repository.NewCommentRepository(gormDB))
+	inbound := services.NewInboundEmailService(addresses, repository.NewUserRepository(gormDB), comments)
+
+	message := func(from string, to string, extra string) string {
+		return "From: " + from + "\r\nTo: " + to + "\r\n" + extra + "\r\nThanks, fixed.\r\n\r\n> quoted\r\n"
+	}
+
+	tests := []struct {
+		name string
+		raw  string
+		code string
+	}{
+		{
+			name: "owner",
+			raw:  message("Ann <ANN@example.com>", addresses.For(annFeedback.ID, ann.ID), ""),
+		},
+		{
+			name: "not a reply address",
+			raw:  message(ann.Email, "support@"+testReplyDomain, ""),
+			code: services.CodeNotFound,
+		},
+		{
+			name: "foreign domain",
+			raw:  message(ann.Email, strings.Replace(addresses.For(annFeedback.ID, ann.ID), testReplyDomain, "example.org", 1), ""),
+			code: services.CodeNotFound,
+		},
+		{
+			name: "forwarded to someone else",
+			raw:  message(bob.Email, addresses.For(annFeedback.ID, ann.ID), ""),
+			code: services.CodeForbidden,
+		},
+		{
+			name: "feedback owned by someone else",
+			raw:  message(bob.Email, addresses.For(annFeedback.ID, bob.ID), ""),
+			code: services.CodeNotFound,
+		},
+		{
+			name: "unknown user",
+			raw:  message(ann.Email, addresses.For(annFeedback.ID, 999), ""),
+			code: services.CodeNotFound,
+		},
+		{
+			name: "malformed",
+			raw:  "not a message",
+			code: services.CodeInvalidRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comment, err := inbound.Receive(strings.NewReader(tt.raw))
+			if tt.code != "" {
+				var serviceErr *services.Error
+				if !errors.As(err, &serviceErr) || serviceErr.Code != tt.code {
+					t.Fatalf("Receive = %v, want %s", err, tt.code)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Receive: %v", err)
+			}
+			if comment.FeedbackID != annFeedback.ID || comment.AuthorID != ann.ID || comment.Body != "Thanks, fixed." {
+				t.Fatalf("comment = %+v", comment)
+			}
+		})
+	}
+
+	comment, err := inbound.Receive(strings.NewReader(message(ann.Email, addresses.For(annFeedback.ID, ann.ID), "Auto-Submitted: auto-replied\r\n")))
+	if err != nil || comment != nil {
+		t.Fatalf("automatic reply = %v, %v, want it ignored", comment, err)
+	}
+
+	var count int64
+	gormDB.Model(&models.Comment{}).Count(&count)
+	if count != 1 {
+		t.Fatalf("%d comments stored, want 1", count)
+	}
+}
@@ -0,0 +1,302 @@
+package services
+
+import (
+	"errors"
+	"feedback-app/i18n"
+	"feedback-app/models"
+	"feedback-app/platform/email"
+	"feedback-app/platform/push"
+	"feedback-app/repository"
+	"feedback-app/utils"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Notification kinds a user can unsubscribe from. Board post subscriptions
+// use PostNotificationKind.
+const (
+	NotifyReplies = "replies"
+	NotifyStatus  = "status"
+	NotifyAll     = "all"
+)
+
+const postKindPrefix = "post-"
+
+// unsubscribePurpose separates unsubscribe signatures from other uses of the
+// signing secret.
+const unsubscribePurpose = "unsubscribe"
+
+const maxExcerptLength = 200
+
+type NotificationConfig struct {
+	AppURL string
+	Secret string
+	// ReplyDomain enables answering notifications by email; see
+	// ReplyAddresses.
+	ReplyDomain string
+}
+
+// NotificationService tells feedback submitters about staff replies and
+// status changes, and board subscribers about activity on posts, by email
+// and, when a push client is configured, by push.
+// Delivery problems are logged rather than returned so they never fail the
+// staff action that triggered them.
+type NotificationService struct {
+	userRepo    repository.UserStore
+	boardRepo   repository.BoardStore
+	emailClient email.Client
+	pushClient  push.Client
+	templates   *email.TemplateRegistry
+	replies     *ReplyAddresses
+	appURL      string
+	secret      string
+}
+
+func NewNotificationService(uRepo repository.UserStore, bRepo repository.BoardStore, emailClient email.Client, pushClient push.Client, templates *email.TemplateRegistry, cfg NotificationConfig) *NotificationService {
+	return &NotificationService{
+		userRepo:    uRepo,
+		boardRepo:   bRepo,
+		emailClient: emailClient,
+		pushClient:  pushClient,
+		templates:   templates,
+		replies:     NewReplyAddresses(cfg.Secret, cfg.ReplyDomain),
+		appURL:      cfg.AppURL,
+		secret:      cfg.Secret,
+	}
+}
+
+// FeedbackReplied notifies the submitter of feedback about comment, unless
+// they wrote it themselves or opted out.
+func (s *NotificationService) FeedbackReplied(feedback *models.Feedback, comment *models.Comment) {
+	if comment.AuthorID == feedback.SubmitterID() {
+		return
+	}
+	user, ok := s.submitter(feedback)
+	if !ok || !user.NotifyOnReply {
+		return
+	}
+
+	locale := i18n.Preferred(user.Locale)
+	s.send(user, feedback.ID, NotifyReplies, "reply", i18n.T(locale, "New reply to your feedback"), excerpt(comment.Body), map[string]interface{}{
+		"Feedback": excerpt(feedback.Content),
+		"Reply":    comment.Body,
+	})
+}
+
+// FeedbackStatusChanged notifies the submitter of feedback that its status
+// is now status, unless they opted out, and the subscribers of its board
+// post if it is published.
+func (s *NotificationService) FeedbackStatusChanged(feedback *models.Feedback, status string) {
+	if user, ok := s.submitter(feedback); ok && user.NotifyOnStatus {
+		locale := i18n.Preferred(user.Locale)
+		label := i18n.T(locale, StatusLabel(status))
+		s.send(user, feedback.ID, NotifyStatus, "status_changed", i18n.T(locale, "Your feedback is now: %s", label), excerpt(feedback.Content), map[string]interface{}{
+			"Feedback": excerpt(feedback.Content),
+			"Status":   label,
+		})
+	}
+
+	post, err := s.boardRepo.FindPostByFeedback(feedback.ID)
+	if err != nil || post.PublishedAt == nil {
+		return
+	}
+	for _, user := range s.subscribers(post.ID, feedback.SubmitterID()) {
+		locale := i18n.Preferred(user.Locale)
+		label := i18n.T(locale, StatusLabel(status))
+		s.send(user, 0, PostNotificationKind(post.ID), "post_status_changed", i18n.T(locale, "“%s” is now: %s", post.Title, label), post.Title, map[string]interface{}{
+			"Title":  post.Title,
+			"Status": label,
+		})
+	}
+}
+
+// FeedbackShipped tells the submitter of feedback, unless they turned off
+// status updates, and the subscribers of its board post that it shipped in
+// release. post is nil when the feedback is not on the board.
+func (s *NotificationService) FeedbackShipped(feedback *models.Feedback, release *models.Release, post *models.Post) {
+	data := func(item string, own bool) map[string]interface{} {
+		return map[string]interface{}{
+			"Item":    item,
+			"Own":     own,
+			"Version": release.Version,
+			"Release": release.Title,
+			"Notes":   release.Notes,
+		}
+	}
+
+	if user, ok := s.submitter(feedback); ok && user.NotifyOnStatus {
+		locale := i18n.Preferred(user.Locale)
+		s.send(user, feedback.ID, NotifyStatus, "shipped", i18n.T(locale, "Your feedback shipped in %s", release.Version), excerpt(feedback.Content), data(excerpt(feedback.Content), true))
+	}
+
+	if post == nil {
+		return
+	}
+	for _, user := range s.subscribers(post.ID, feedback.SubmitterID()) {
+		locale := i18n.Preferred(user.Locale)
+		s.send(user, 0, PostNotificationKind(post.ID), "shipped", i18n.T(locale, "“%s” shipped in %s", post.Title, release.Version), post.Title, data(post.Title, false))
+	}
+}
+
+// PostCommented tells the subscribers of post, except its author, about a
+// new comment.
+func (s *NotificationService) PostCommented(post *models.Post, comment *models.PostComment) {
+	for _, user := range s.subscribers(post.ID, comment.AuthorID) {
+		locale := i18n.Preferred(user.Locale)
+		s.send(user, 0, PostNotificationKind(post.ID), "post_comment", i18n.T(locale, "New comment on “%s”", post.Title), excerpt(comment.Body), map[string]interface{}{
+			"Title":   post.Title,
+			"Comment": comment.Body,
+		})
+	}
+}
+
+// PostNotificationKind is the unsubscribe kind for a board post
+// subscription.
+func PostNotificationKind(postID uint) string {
+	return postKindPrefix + strconv.FormatUint(uint64(postID), 10)
+}
+
+// UnsubscribeURL returns the signed one-click unsubscribe link for kind.
+func (s *NotificationService) UnsubscribeURL(userID uint, kind string) string {
+	id := strconv.FormatUint(uint64(userID), 10)
+	query := url.Values{
+		"user": {id},
+		"kind": {kind},
+		"sig":  {utils.Sign(s.secret, unsubscribePurpose, id, kind)},
+	}
+	return s.appURL + "/notifications/unsubscribe?" + query.Encode()
+}
+
+// Unsubscribe turns off the notifications of kind for userID after checking
+// the signature from UnsubscribeURL.
+func (s *NotificationService) Unsubscribe(userID uint, kind string, signature string) error {
+	id := strconv.FormatUint(uint64(userID), 10)
+	if !utils.VerifySignature(s.secret, signature, unsubscribePurpose, id, kind) {
+		return ErrInvalidToken
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return NotFound("User not found")
+		}
+		return err
+	}
+
+	if strings.HasPrefix(kind, postKindPrefix) {
+		postID, err := strconv.ParseUint(strings.TrimPrefix(kind, postKindPrefix), 10, 64)
+		if err != nil {
+			return Invalid("Unknown notification kind")
+		}
+		return s.boardRepo.Unsubscribe(uint(postID), user.ID)
+	}
+
+	onReply, onStatus := user.NotifyOnReply, user.NotifyOnStatus
+	switch kind {
+	case NotifyReplies:
+		onReply = false
+	case NotifyStatus:
+		onStatus = false
+	case NotifyAll:
+		onReply, onStatus = false, false
+	default:
+		return Invalid("Unknown notification kind")
+	}
+	return s.userRepo.UpdateNotifications(userID, onReply, onStatus)
+}
+
+func (s *NotificationService) recipient(userID uint) (*models.User, bool) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		log.Printf("Failed to load user %d for notification: %v", userID, err)
+		return nil, false
+	}
+	return user, true
+}
+
+// submitter returns the user who submitted feedback. Guests are never
+// notified: their contact address is unverified, so mailing it would let
+// anyone send our emails to a stranger.
+func (s *NotificationService) submitter(feedback *models.Feedback) (*models.User, bool) {
+	if feedback.UserID == nil {
+		return nil, false
+	}
+	return s.recipient(*feedback.UserID)
+}
+
+// subscribers returns the subscribers of postID except skipUserID.
+func (s *NotificationService) subscribers(postID uint, skipUserID uint) []*models.User {
+	users, err := s.boardRepo.Subscribers(postID)
+	if err != nil {
+		log.Printf("Failed to load subscribers of post %d: %v", postID, err)
+		return nil
+	}
+	recipients := make([]*models.User, 0, len(users))
+	for i := range users {
+		if users[i].ID != skipUserID {
+			recipients = append(recipients, &users[i])
+		}
+	}
+	return recipients
+}
+
+// send renders and queues one notification. A feedbackID lets the recipient
+// answer by email; board notifications pass 0 since only the submitter can
+// post in the feedback thread.
+func (s *NotificationService) send(user *models.User, feedbackID uint, kind string, template string, subject string, pushBody string, data map[string]interface{}) {
+	locale := i18n.Preferred(user.Locale)
+	unsubscribeURL := s.UnsubscribeURL(user.ID, kind)
+	data["UnsubscribeURL"] = unsubscribeURL
+	data["ReplyByEmail"] = feedbackID != 0 && s.replies.Enabled()
+
+	rendered, err := s.templates.Render(template, locale, data)
+	if err != nil {
+		log.Printf("Failed to render %s notification: %v", template, err)
+		return
+	}
+
+	headers := map[string]string{
+		// RFC 8058 one-click unsubscribe.
+		"List-Unsubscribe":      "<" + unsubscribeURL + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
+	if feedbackID != 0 && s.replies.Enabled() {
+		headers["Reply-To"] = s.replies.For(feedbackID, user.ID)
+	}
+
+	err = s.emailClient.Send(email.Message{
+		To:      user.Email,
+		Subject: subject,
+		Text:    rendered.Text,
+		HTML:    rendered.HTML,
+		Headers: headers,
+	})
+	if err != nil {
+		log.Printf("Failed to queue %s notification for user %d: %v", template, user.ID, err)
+	}
+
+	if s.pushClient != nil {
+		if err := s.pushClient.Notify(user.ID, subject, pushBody); err != nil {
+			log.Printf("Failed to push %s notification to user %d: %v", template, user.ID, err)
+		}
+	}
+}
+
+// StatusLabel turns a status such as "in_review" into the English label
+// "in review", which is also its i18n key.
+func StatusLabel(status string) string {
+	return strings.ReplaceAll(status, "_", " ")
+}
+
+func excerpt(text string) string {
+	text = strings.TrimSpace(text)
+	runes := []rune(text)
+	if len(runes) <= maxExcerptLength {
+		return text
+	}
+	return strings.TrimSpace(string(runes[:maxExcerptLength])) + "…"
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"errors"
+	"feedback-app/models"
+	"feedback-app/repository"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxReleaseVersionLength = 50
+	adminReleaseLimit       = 100
+)
+
+// ReleaseService keeps the public changelog. Staff draft a release, link the
+// feedback it resolves and publish it, which marks that feedback done and
+// tells the people who asked for it.
+type ReleaseService struct {
+	releaseRepo repository.ReleaseStore
+	board       *BoardService
+	notifier    *NotificationService
+}
+
+func NewReleaseService(rRepo repository.ReleaseStore, board *BoardService, notifier *NotificationService) *ReleaseService {
+	return &ReleaseService{
+		releaseRepo: rRepo,
+		board:       board,
+		notifier:    notifier,
+	}
+}
+
+// Changelog returns a page of published releases, newest first, with the
+// board posts they shipped, and the number of published releases. Linked
+// feedback that is not on the board stays private.
+func (s *ReleaseService) Changelog(limit int, offset int) ([]models.Release, int64, error) {
+	if limit <= 0 {
+		limit = defaultBoardPageSize
+	}
+	if limit > maxBoardPageSize {
+		limit = maxBoardPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	releases, total, err := s.releaseRepo.List(true, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var feedbackIDs []uint
+	for _, release := range releases {
+		for _, feedback := range release.Feedback {
+			feedbackIDs = append(feedbackIDs, feedback.ID)
+		}
+	}
+	posts, err := s.board.PublishedPosts(feedbackIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range releases {
+		releases[i].Posts = []models.Post{}
+		for _, feedback := range releases[i].Feedback {
+			if post, ok := posts[feedback.ID]; ok {
+				releases[i].Posts = append(releases[i].Posts, post)
+			}
+		}
+	}
+	return releases, total, nil
+}
+
+// All returns drafts and the latest published releases for the admin.
+func (s *ReleaseService) All() ([]models.Release, error) {
+	releases, _, err := s.releaseRepo.List(false, adminReleaseLimit, 0)
+	return releases, err
+}
+
+func (s *ReleaseService) Get(id uint) (*models.Release, error) {
+	release, err := s.releaseRepo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, NotFound("Release not found")
+	}
+	return release, err
+}
+
+// Create drafts a release.
+func (s *ReleaseService) Create(version string, title string, notes string) (*models.Release, error) {
+	release := &models.Release{}
+	if err := s.apply(release, version, title, notes); err != nil {
+		return nil, err
+	}
+	if err := s.releaseRepo.Create(release); err != nil {
+		return nil, err
+	}
+	return release, nil
+}
+
+// Update changes a release and the feedback it is linked to. Feedback
+// linked after publishing is not marked done or notified.
+func (s *ReleaseService) Update(id uint, version string, title string, notes string, feedbackIDs []uint) error {
+	release, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := s.apply(release, version, title, notes); err != nil {
+		return err
+	}
+	if err := s.releaseRepo.Update(release); err != nil {
+		return err
+	}
+
+	seen := make(map[uint]bool, len(feedbackIDs))
+	unique := make([]uint, 0, len(feedbackIDs))
+	for _, id := range feedbackIDs {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	err = s.releaseRepo.ReplaceFeedback(release, unique)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return NotFound("Feedback not found")
+	}
+	return err
+}
+
+// Publish adds a release to the changelog, marks its feedback done and
+// notifies each item's submitter and board subscribers. The usual status
+// change notification is not sent on top.
+func (s *ReleaseService) Publish(id uint) error {
+	release, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	if release.PublishedAt != nil {
+		return Conflict("Release is already published")
+	}
+
+	// Only the request that flips published_at notifies, so a double submit
+	// cannot send every email twice.
+	published, err := s.releaseRepo.Publish(release, time.Now())
+	if err != nil {
+		return err
+	}
+	if !published {
+		return Conflict("Release is already published")
+	}
+
+	feedbackIDs := make([]uint, 0, len(release.Feedback))
+	for _, feedback := range release.Feedback {
+		feedbackIDs = append(feedbackIDs, feedback.ID)
+	}
+	posts, err := s.board.PublishedPosts(feedbackIDs)
+	if err != nil {
+		return err
+	}
+	for i := range release.Feedback {
+		var post *models.Post
+		if p, ok := posts[release.Feedback[i].ID]; ok {
+			post = &p
+		}
+		s.notifier.FeedbackShipped(&release.Feedback[i], release, post)
+	}
+	return nil
+}
+
+func (s *ReleaseService) apply(release *models.Release, version string, title string, notes string) error {
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return Invalid("Version is required")
+	}
+	if utf8.RuneCountInString(version) > maxReleaseVersionLength {
+		return Invalidf("Versions may be at most %d characters", maxReleaseVersionLength)
+	}
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return Invalid("Title is required")
+	}
+	if utf8.RuneCountInString(title) > maxPostTitleLength {
+		return Invalidf("Titles may be at most %d characters", maxPostTitleLength)
+	}
+
+	existing, err := s.releaseRepo.FindByVersion(version)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err == nil && existing.ID != release.ID {
+		return Invalid("Version already exists")
+	}
+
+	release.Version = version
+	release.Title = title
+	release.Notes = strings.TrimSpace(notes)
+	return nil
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"feedback-app/models"
+	"feedback-app/repository"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxSiteNameLength = 100
+	maxSiteOrigins    = 20
+)
+
+// SiteService manages the web properties that embed the feedback widget.
+type SiteService struct {
+	siteRepo repository.SiteStore
+	appURL   string
+}
+
+func NewSiteService(sRepo repository.SiteStore, appURL string) *SiteService {
+	return &SiteService{
+		siteRepo: sRepo,
+		appURL:   strings.TrimRight(appURL, "/"),
+	}
+}
+
+func (s *SiteService) All() ([]models.Site, error) {
+	return s.siteRepo.All()
+}
+
+func (s *SiteService) Get(id uint) (*models.Site, error) {
+	site, err := s.siteRepo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, NotFound("Site not found")
+	}
+	return site, err
+}
+
+// ForKey returns the site with the public key from an embed code.
+func (s *SiteService) ForKey(key string) (*models.Site, error) {
+	site, err := s.siteRepo.FindByPublicKey(key)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, NotFound("Site not found")
+	}
+	return site, err
+}
+
+// Create registers a site under a new public key. origins lists the allowed
+// origins separated by whitespace or commas.
+func (s *SiteService) Create(name string, origins string) (*models.Site, error) {
+	key := make([]byte, 12)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	site := &models.Site{PublicKey: "site_" + hex.EncodeToString(key)}
+	if err := s.apply(site, name, origins); err != nil {
+		return nil, err
+	}
+	if err := s.siteRepo.Create(site); err != nil {
+		return nil, err
+	}
+	return site, nil
+}
+
+// Update renames a site and replaces its allowed origins.
+func (s *SiteService) Update(id uint, name string, origins string) error {
+	site, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := s.apply(site, name, origins); err != nil {
+		return err
+	}
+	return s.siteRepo.Update(site)
+}
+
+// EmbedCode returns the script tag that adds the widget to a page of site.
+func (s *SiteService) EmbedCode(site *models.Site) string {
+	return fmt.Sprintf(`<script src="%s/widget.js" data-site="%s" async></script>`,
+		html.EscapeString(s.appURL), html.EscapeString(site.PublicKey))
+}
+
+func (s *SiteService) apply(site *models.Site, name string, origins string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Invalid("Name is required")
+	}
+	if utf8.RuneCountInString(name) > maxSiteNameLength {
+		return Invalidf("Names may be at most %d characters", maxSiteNameLength)
+	}
+
+	normalized, err := normalizeOrigins(origins)
+	if err != nil {
+		return err
+	}
+
+	site.Name = name
+	site.AllowedOrigins = strings.Join(normalized, "\n")
+	return nil
+}
+
+// normalizeOrigins parses a list of origins such as https://example.com into
+// the form browsers send in the Origin header, without duplicates. Like
+// browsers, it lowercases the origin and drops the scheme's default port.
+func normalizeOrigins(list string) ([]string, error) {
+	fields := strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\r' || r == '\n'
+	})
+
+	seen := make(map[string]bool, len(fields))
+	origins := make([]string, 0, len(fields))
+	for _, field := range fields {
+		u, err := url.Parse(field)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+			u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+			return nil, Invalidf("%s is not a valid origin", field)
+		}
+		host := u.Host
+		if port := u.Port(); (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+			host = strings.TrimSuffix(host, ":"+port)
+		}
+		origin := strings.ToLower(u.Scheme + "://" + host)
+		if !seen[origin] {
+			seen[origin] = true
+			origins = append(origins, origin)
+		}
+	}
+
+	if len(origins) == 0 {
+		return nil, Invalid("At least one allowed origin is required")
+	}
+	if len(origins) > maxSiteOrigins {
+		return nil, Invalidf("A site may have at most %d allowed origins", maxSiteOrigins)
+	}
+	return origins, nil
+}
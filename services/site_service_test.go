@@ -0,0 +1,63 @@
+package services_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"feedback-app/repository"
+	"feedback-app/services"
+)
+
+func TestSiteOrigins(t *testing.T) {
+	sites := services.NewSiteService(repository.NewSiteRepository(newTestDB(t)), "https://feedback.example.com")
+
+	tests := []struct {
+		name    string
+		origins string
+		want    []string
+	}{
+		{"single", "https://shop.example.com", []string{"https://shop.example.com"}},
+		{"case", "HTTPS://Shop.Example.COM", []string{"https://shop.example.com"}},
+		{"trailing slash", "https://shop.example.com/", []string{"https://shop.example.com"}},
+		{"default https port", "https://shop.example.com:443", []string{"https://shop.example.com"}},
+		{"default http port", "http://localhost:80", []string{"http://localhost"}},
+		{"other port", "http://localhost:8080 https://shop.example.com:8443", []string{"http://localhost:8080", "https://shop.example.com:8443"}},
+		{"https on port 80", "https://shop.example.com:80", []string{"https://shop.example.com:80"}},
+		{"ipv6", "http://[::1]:80, http://[::1]:3000", []string{"http://[::1]", "http://[::1]:3000"}},
+		{"separators and duplicates", "https://a.example.com,\nhttps://b.example.com\thttps://A.example.com:443/", []string{"https://a.example.com", "https://b.example.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site, err := sites.Create("Shop", tt.origins)
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if got := site.Origins(); strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Fatalf("origins = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	invalid := []struct {
+		name    string
+		origins string
+	}{
+		{"empty", " , "},
+		{"path", "https://shop.example.com/checkout"},
+		{"query", "https://shop.example.com/?a=b"},
+		{"fragment", "https://shop.example.com/#top"},
+		{"user info", "https://user@shop.example.com"},
+		{"scheme", "ftp://shop.example.com"},
+		{"no scheme", "shop.example.com"},
+		{"no host", "https://"},
+		{"one of several", "https://shop.example.com https://shop.example.com/path"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := sites.Create("Shop", tt.origins); !errors.Is(err, services.ErrInvalidRequest) {
+				t.Fatalf("Create(%q) = %v, want ErrInvalidRequest", tt.origins, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+// Package templates embeds the admin dashboard pages, the email templates and
+// the feedback widget scripts so that binaries do not depend on the working
+// directory.
+package templates
+
+import "embed"
+
+//go:embed admin email widget
+var FS embed.FS
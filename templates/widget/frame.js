@@ -0,0 +1,88 @@
+// Runs inside the widget iframe. It solves the proof-of-work challenge in
+// the background while the visitor types, then posts the feedback.
+(function () {
+    'use strict';
+
+    var form = document.getElementById('feedback-form');
+    var status = document.getElementById('status');
+    var base = '/widget/' + encodeURIComponent(form.dataset.site);
+    var solved = null;
+
+    function zeroBits(hash) {
+        var bits = 0;
+        for (var i = 0; i < hash.length; i++) {
+            if (hash[i] === 0) {
+                bits += 8;
+                continue;
+            }
+            return bits + Math.clz32(hash[i]) - 24;
+        }
+        return bits;
+    }
+
+    async function solve() {
+        var response = await fetch(base + '/challenge');
+        if (!response.ok) {
+            throw new Error('challenge');
+        }
+        var challenge = await response.json();
+        var encoder = new TextEncoder();
+        for (var n = 0; ; n++) {
+            var data = encoder.encode(challenge.challenge + ':' + n);
+            var hash = new Uint8Array(await crypto.subtle.digest('SHA-256', data));
+            if (zeroBits(hash) >= challenge.difficulty) {
+                return { challenge: challenge.challenge, solution: String(n) };
+            }
+        }
+    }
+
+    function start() {
+        solved = solve();
+        solved.catch(function () {});
+    }
+
+    form.addEventListener('submit', async function (event) {
+        event.preventDefault();
+        var button = form.querySelector('button');
+        button.disabled = true;
+        status.textContent = 'Sending…';
+
+        try {
+            var proof = await solved;
+            var body = {
+                content: form.elements.content.value,
+                category: form.elements.category.value,
+                challenge: proof.challenge,
+                solution: proof.solution
+            };
+            if (form.elements.email.value) {
+                body.email = form.elements.email.value;
+            }
+            if (form.dataset.page) {
+                body.metadata = { page: form.dataset.page };
+            }
+
+            var response = await fetch(base + '/feedback', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify(body)
+            });
+            var result = await response.json();
+            if (!response.ok) {
+                status.textContent = result.detail || 'Sending failed.';
+                start();
+                return;
+            }
+            form.reset();
+            status.textContent = result.message;
+            start();
+        } catch (err) {
+            status.textContent = 'Sending failed. Please try again.';
+            start();
+        } finally {
+            button.disabled = false;
+        }
+    });
+
+    start();
+})();
@@ -0,0 +1,48 @@
+// Feedback widget. Embed with
+//   <script src="https://feedback.example.com/widget.js" data-site="site_..." async></script>
+// It adds a button that opens the feedback form in an iframe served by the
+// feedback server, so the page never handles the submission itself.
+(function () {
+    'use strict';
+
+    var script = document.currentScript;
+    if (!script || !script.dataset.site) {
+        return;
+    }
+
+    var base = new URL(script.src).origin;
+    var label = script.dataset.label || 'Feedback';
+    var frame = null;
+
+    function toggle() {
+        if (frame) {
+            frame.hidden = !frame.hidden;
+            return;
+        }
+        frame = document.createElement('iframe');
+        frame.title = label;
+        frame.src = base + '/widget/' + encodeURIComponent(script.dataset.site) + '/frame?page=' +
+            encodeURIComponent(window.location.href.slice(0, 256));
+        frame.style.cssText = 'position:fixed;right:16px;bottom:68px;width:340px;height:430px;' +
+            'max-width:calc(100% - 32px);border:1px solid #dddddd;border-radius:8px;background:#ffffff;' +
+            'box-shadow:0 4px 16px rgba(0,0,0,0.15);z-index:2147483647;';
+        document.body.appendChild(frame);
+    }
+
+    function mount() {
+        var button = document.createElement('button');
+        button.type = 'button';
+        button.textContent = label;
+        button.style.cssText = 'position:fixed;right:16px;bottom:16px;z-index:2147483647;' +
+            'background:#1a73e8;color:#ffffff;border:0;border-radius:20px;padding:10px 18px;' +
+            'font:14px Arial,sans-serif;cursor:pointer;box-shadow:0 2px 8px rgba(0,0,0,0.2);';
+        button.addEventListener('click', toggle);
+        document.body.appendChild(button);
+    }
+
+    if (document.body) {
+        mount();
+    } else {
+        document.addEventListener('DOMContentLoaded', mount);
+    }
+})();